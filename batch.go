@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	rae "github.com/rae-api-com/go-rae"
+)
+
+const (
+	// defaultBatchJobs is the number of words looked up at once
+	defaultBatchJobs = 4
+	// defaultBatchRate is the number of API requests started per second
+	defaultBatchRate = 5.0
+)
+
+// rateLimitedClient spaces out the requests to next so that at most rate of
+// them start per second. It wraps the API client only, cached and offline
+// lookups are not limited
+type rateLimitedClient struct {
+	next     dictionary
+	interval time.Duration
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+func newRateLimitedClient(next dictionary, rate float64) *rateLimitedClient {
+	return &rateLimitedClient{next: next, interval: time.Duration(float64(time.Second) / rate)}
+}
+
+// wait blocks until the next request may start
+func (c *rateLimitedClient) wait(ctx context.Context) error {
+	c.mu.Lock()
+	next := c.last.Add(c.interval)
+	if now := time.Now(); next.Before(now) {
+		next = now
+	}
+	c.last = next
+	c.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(next))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (c *rateLimitedClient) Word(ctx context.Context, word string) (rae.WordEntry, error) {
+	if err := c.wait(ctx); err != nil {
+		return rae.WordEntry{}, err
+	}
+	return c.next.Word(ctx, word)
+}
+
+func (c *rateLimitedClient) Search(ctx context.Context, terms string) ([]rae.SearchResult, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	return c.next.Search(ctx, terms)
+}
+
+// readBatchWords reads one word per line, skipping blank lines, # comments
+// and repeated words
+func readBatchWords(r io.Reader) ([]string, error) {
+	var words []string
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" || strings.HasPrefix(word, "#") || seen[word] {
+			continue
+		}
+		seen[word] = true
+		words = append(words, word)
+	}
+	return words, scanner.Err()
+}
+
+// batchWriter writes the lookups of a batch in one of its output formats
+type batchWriter interface {
+	Write(doc jsonLookup) error
+}
+
+type jsonlBatchWriter struct{ enc *json.Encoder }
+
+func (w jsonlBatchWriter) Write(doc jsonLookup) error {
+	return w.enc.Encode(doc)
+}
+
+type csvBatchWriter struct{ cw *csv.Writer }
+
+var batchCSVHeader = []string{"query", "status", "word", "definitions", "suggestions", "error"}
+
+func (w csvBatchWriter) Write(doc jsonLookup) error {
+	var word string
+	var definitions []string
+	if doc.Entry != nil {
+		word = doc.Entry.Word
+		definitions = entryDefinitions(*doc.Entry)
+	}
+
+	suggestions := doc.Suggestions
+	for _, hit := range doc.Results {
+		suggestions = append(suggestions, hit.Word)
+	}
+
+	err := w.cw.Write([]string{
+		doc.Query,
+		string(doc.Status),
+		word,
+		strings.Join(definitions, " | "),
+		strings.Join(suggestions, " "),
+		doc.Error,
+	})
+	if err != nil {
+		return err
+	}
+	w.cw.Flush()
+	return w.cw.Error()
+}
+
+type markdownBatchWriter struct{ w io.Writer }
+
+func (w markdownBatchWriter) Write(doc jsonLookup) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", doc.Query)
+
+	switch {
+	case doc.Entry != nil:
+		if doc.Lemma != nil {
+			fmt.Fprintf(&b, tr("*Forma de **%s**: %s*\n\n"), doc.Lemma.Lemma, strings.Join(doc.Lemma.Analyses, "; "))
+		}
+		for _, def := range entryDefinitions(*doc.Entry) {
+			fmt.Fprintf(&b, "- %s\n", def)
+		}
+	case len(doc.Suggestions) > 0:
+		fmt.Fprintf(&b, tr("*No encontrada.* Quizá: %s\n"), strings.Join(doc.Suggestions, ", "))
+	case len(doc.Results) > 0:
+		words := make([]string, 0, len(doc.Results))
+		for _, hit := range doc.Results {
+			words = append(words, hit.Word)
+		}
+		fmt.Fprintf(&b, tr("*No encontrada.* Parecidas: %s\n"), strings.Join(words, ", "))
+	default:
+		b.WriteString(tr("*No encontrada.*\n"))
+	}
+	b.WriteString("\n")
+
+	_, err := io.WriteString(w.w, b.String())
+	return err
+}
+
+// entryDefinitions flattens the definitions of every meaning of entry
+func entryDefinitions(entry rae.WordEntry) []string {
+	var defs []string
+	for _, meaning := range entry.Meanings {
+		for _, def := range meaning.Definitions {
+			defs = append(defs, def.Raw)
+		}
+	}
+	return defs
+}
+
+func newBatchWriter(w io.Writer, format outputFormat, header bool) (batchWriter, error) {
+	switch format {
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		if header {
+			if err := cw.Write(batchCSVHeader); err != nil {
+				return nil, err
+			}
+			cw.Flush()
+		}
+		return csvBatchWriter{cw}, cw.Error()
+	case formatJSONL:
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		return jsonlBatchWriter{enc}, nil
+	default:
+		return markdownBatchWriter{w}, nil
+	}
+}
+
+// completedWords reads the queries already written to a batch output, so an
+// interrupted batch resumes where it stopped
+func completedWords(r io.Reader, format outputFormat) (map[string]bool, error) {
+	done := make(map[string]bool)
+
+	switch format {
+	case formatCSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		for {
+			record, err := cr.Read()
+			if errors.Is(err, io.EOF) {
+				return done, nil
+			}
+			if err != nil {
+				// The last row may have been cut short by the interruption
+				return done, nil
+			}
+			if len(record) > 0 && record[0] != batchCSVHeader[0] {
+				done[record[0]] = true
+			}
+		}
+	case formatJSONL:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, 16<<20)
+		for scanner.Scan() {
+			var doc jsonLookup
+			if err := json.Unmarshal(scanner.Bytes(), &doc); err == nil && doc.Query != "" {
+				done[doc.Query] = true
+			}
+		}
+		return done, scanner.Err()
+	default:
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			if word, ok := strings.CutPrefix(scanner.Text(), "## "); ok {
+				done[strings.TrimSpace(word)] = true
+			}
+		}
+		return done, scanner.Err()
+	}
+}
+
+// batchReport counts the outcomes of a batch. Failed words are left out of
+// the output so that resuming retries them
+type batchReport struct {
+	found    int
+	missing  []jsonLookup
+	failed   []jsonLookup
+	skipped  int
+	pending  int
+	canceled bool
+	// resumable is set when the command takes --resume to continue
+	resumable bool
+}
+
+func (r batchReport) print(w io.Writer) {
+	fmt.Fprintf(w, tr("Encontradas: %d  No encontradas: %d  Errores: %d"), r.found, len(r.missing), len(r.failed))
+	if r.skipped > 0 {
+		fmt.Fprintf(w, tr("  Ya procesadas: %d"), r.skipped)
+	}
+	fmt.Fprintln(w)
+
+	if len(r.missing) > 0 {
+		fmt.Fprintln(w, tr("\nNo encontradas:"))
+		for _, doc := range r.missing {
+			suggestions := doc.Suggestions
+			for _, hit := range doc.Results {
+				suggestions = append(suggestions, hit.Word)
+			}
+			if len(suggestions) == 0 {
+				fmt.Fprintf(w, "  %s\n", doc.Query)
+				continue
+			}
+			fmt.Fprintf(w, tr("  %-20s ¿quisiste decir: %s?\n"), doc.Query, strings.Join(suggestions, ", "))
+		}
+	}
+	if len(r.failed) > 0 {
+		fmt.Fprintln(w, tr("\nErrores:"))
+		for _, doc := range r.failed {
+			fmt.Fprintf(w, "  %-20s %s\n", doc.Query, doc.Error)
+		}
+	}
+	switch {
+	case r.canceled && r.resumable:
+		fmt.Fprintf(w, tr("\nInterrumpido con %d palabras pendientes: repite el comando con --resume para continuar\n"), r.pending)
+	case r.canceled:
+		fmt.Fprintf(w, tr("\nInterrumpido con %d palabras pendientes\n"), r.pending)
+	}
+}
+
+func (r batchReport) exitCode() int {
+	switch {
+	case r.canceled || len(r.failed) > 0:
+		return exitError
+	case len(r.missing) > 0:
+		return exitNotFound
+	default:
+		return exitFound
+	}
+}
+
+// lookupBatch looks words up with up to jobs of them in flight, writing the
+// results in input order as they complete. Words missing from the offline
+// dictionary only count as not found when offline mode is forced, otherwise
+// they failed because the API could not be reached
+func lookupBatch(ctx context.Context, cli dictionary, words []string, jobs int, offline bool, out batchWriter) (batchReport, error) {
+	var report batchReport
+
+	type result struct {
+		index int
+		doc   jsonLookup
+	}
+	indexes := make(chan int)
+	results := make(chan result)
+
+	var wg sync.WaitGroup
+	for range max(jobs, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results <- result{i, lookupJSON(ctx, cli, words[i])}
+			}
+		}()
+	}
+	go func() {
+		defer close(indexes)
+		for i := range words {
+			select {
+			case indexes <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Results are held back until every word before them is written, so the
+	// output keeps the order of the input
+	pending := make(map[int]jsonLookup)
+	next := 0
+	var writeErr error
+	for r := range results {
+		pending[r.index] = r.doc
+		for doc, ok := pending[next]; ok; doc, ok = pending[next] {
+			delete(pending, next)
+			next++
+
+			failed := doc.Status == statusError
+			if failed && offline && errors.Is(doc.err, errOfflineMiss) {
+				doc.Status, doc.Error, failed = statusNotFound, "", false
+			}
+			if failed && doc.Error == "" {
+				doc.Error = doc.err.Error()
+			}
+
+			switch {
+			case ctx.Err() != nil && failed:
+				// Cut short by the interruption, retried on resume
+				report.pending++
+				continue
+			case failed:
+				report.failed = append(report.failed, doc)
+				continue
+			case doc.Status == statusFound:
+				report.found++
+			default:
+				report.missing = append(report.missing, doc)
+			}
+			if writeErr == nil {
+				writeErr = out.Write(doc)
+			}
+		}
+	}
+
+	if ctx.Err() != nil {
+		report.canceled = true
+		report.pending += len(words) - next
+	}
+	return report, writeErr
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	rae "github.com/rae-api-com/go-rae"
+)
+
+func TestReadBatchWords(t *testing.T) {
+	input := "casa\n\n  perro \n# comentario\ncasa\ngato\n"
+	want := []string{"casa", "perro", "gato"}
+
+	words, err := readBatchWords(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(words, want) {
+		t.Errorf("words = %q, want %q", words, want)
+	}
+}
+
+func TestCompletedWords(t *testing.T) {
+	tests := []struct {
+		name   string
+		format outputFormat
+		output string
+		want   []string
+	}{
+		{name: "empty csv", format: formatCSV, output: "", want: nil},
+		{name: "csv header", format: formatCSV, output: "query,status,word,definitions,suggestions,error\n", want: nil},
+		{
+			name:   "csv",
+			format: formatCSV,
+			output: "query,status,word,definitions,suggestions,error\ncasa,found,casa,x,,\nperro,not_found,,,,\n",
+			want:   []string{"casa", "perro"},
+		},
+		{
+			name:   "csv cut short",
+			format: formatCSV,
+			output: "query,status,word,definitions,suggestions,error\ncasa,found,casa,x,,\nperro,found,perro,\"mamífero",
+			want:   []string{"casa"},
+		},
+		{
+			name:   "jsonl",
+			format: formatJSONL,
+			output: `{"query":"casa","status":"found"}` + "\n" + `{"query":"perro","status":"not_found"}` + "\n" + `{"query":"ga`,
+			want:   []string{"casa", "perro"},
+		},
+		{
+			name:   "markdown",
+			format: formatMarkdown,
+			output: "## casa\n\n- Edificio para habitar\n\n## perro\n\n*No encontrada.*\n\n",
+			want:   []string{"casa", "perro"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done, err := completedWords(strings.NewReader(tt.output), tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := slices.Sorted(maps.Keys(done)); !slices.Equal(got, tt.want) {
+				t.Errorf("completed = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// docsWriter keeps the documents written by a batch
+type docsWriter struct {
+	docs []jsonLookup
+}
+
+func (w *docsWriter) Write(doc jsonLookup) error {
+	w.docs = append(w.docs, doc)
+	return nil
+}
+
+func TestLookupBatchOffline(t *testing.T) {
+	store := newOfflineStore(filepath.Join(t.TempDir(), "offline.jsonl"))
+	if err := store.Put("casa", rae.WordEntry{Word: "casa"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cli     dictionary
+		offline bool
+		missing int
+		failed  int
+	}{
+		// A word missing offline is not found when offline mode is forced
+		{name: "forced", cli: newOfflineClient(nil, store), offline: true, missing: 1},
+		// and failed when the API could not be reached
+		{name: "unreachable", cli: newOfflineClient(new(downDictionary), store), failed: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clear(lemmaMisses.forms)
+			clear(lemmaMisses.words)
+
+			out := new(docsWriter)
+			report, err := lookupBatch(context.Background(), tt.cli, []string{"casa", "zzz"}, 2, tt.offline, out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.found != 1 || len(report.missing) != tt.missing || len(report.failed) != tt.failed {
+				t.Errorf("found %d, missing %d, failed %d, want 1, %d, %d",
+					report.found, len(report.missing), len(report.failed), tt.missing, tt.failed)
+			}
+			for _, doc := range out.docs {
+				if doc.Error != "" {
+					t.Errorf("%s written with error %q", doc.Query, doc.Error)
+				}
+			}
+		})
+	}
+}
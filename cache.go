@@ -0,0 +1,511 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	rae "github.com/rae-api-com/go-rae"
+)
+
+// dictionary is the subset of *rae.Client used to resolve lookups, so that
+// extra layers such as the disk cache can be stacked in front of the API
+type dictionary interface {
+	Word(ctx context.Context, word string) (rae.WordEntry, error)
+	Search(ctx context.Context, terms string) ([]rae.SearchResult, error)
+}
+
+const (
+	cacheWordsDir  = "words"
+	cacheSearchDir = "search"
+)
+
+type cacheConfig struct {
+	dir       string
+	wordTTL   time.Duration
+	searchTTL time.Duration
+	// maxSize is the size in bytes the cache is pruned down to, 0 means unbounded
+	maxSize int64
+}
+
+func defaultCacheConfig() cacheConfig {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return cacheConfig{
+		dir:       filepath.Join(dir, "rae-tui"),
+		wordTTL:   30 * 24 * time.Hour,
+		searchTTL: 7 * 24 * time.Hour,
+		maxSize:   100 << 20,
+	}
+}
+
+// wordRecord is the on-disk representation of a cached WordEntry. It is also
+// the line format of `rae-tui cache export`
+type wordRecord struct {
+	Key       string        `json:"key"`
+	FetchedAt time.Time     `json:"fetched_at"`
+	Entry     rae.WordEntry `json:"entry"`
+}
+
+type searchRecord struct {
+	Key       string             `json:"key"`
+	FetchedAt time.Time          `json:"fetched_at"`
+	Results   []rae.SearchResult `json:"results"`
+}
+
+// pruneLowWater is the share of cfg.maxSize pruning after a write aims at,
+// so that a full cache is not pruned again on the next write
+const pruneLowWater = 0.9
+
+// diskCache stores one JSON file per lookup under cfg.dir. Every operation is
+// best effort: a broken cache must never prevent a lookup from succeeding
+type diskCache struct {
+	cfg cacheConfig
+	now func() time.Time
+
+	// size estimates the bytes in the cache, measured on the first write and
+	// grown by the writes since, so that they only prune once it goes over
+	// cfg.maxSize. Rewritten lookups count twice until the next prune
+	mu    sync.Mutex
+	size  int64
+	sized bool
+}
+
+func newDiskCache(cfg cacheConfig) *diskCache {
+	return &diskCache{cfg: cfg, now: time.Now}
+}
+
+func cacheKey(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+func (c *diskCache) path(kind, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.cfg.dir, kind, hex.EncodeToString(sum[:16])+".json")
+}
+
+func (c *diskCache) read(kind, key string, v any) error {
+	data, err := os.ReadFile(c.path(kind, key))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (c *diskCache) write(kind, key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(c.path(kind, key), data); err != nil {
+		return err
+	}
+
+	if c.cfg.maxSize > 0 {
+		c.grow(int64(len(data)))
+	}
+	return nil
+}
+
+// grow adds n written bytes to the size estimate and prunes the cache when it
+// goes over cfg.maxSize
+func (c *diskCache) grow(n int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.sized {
+		files, err := c.files()
+		if err != nil {
+			return
+		}
+		for _, f := range files {
+			c.size += f.size
+		}
+		c.sized = true
+	} else {
+		c.size += n
+	}
+
+	if c.size > c.cfg.maxSize {
+		_, size, err := c.prune(int64(float64(c.cfg.maxSize) * pruneLowWater))
+		if err == nil {
+			c.size = size
+		}
+	}
+}
+
+func (c *diskCache) GetWord(word string) (rae.WordEntry, bool) {
+	key := cacheKey(word)
+
+	var rec wordRecord
+	if err := c.read(cacheWordsDir, key, &rec); err != nil || rec.Key != key {
+		return rae.WordEntry{}, false
+	}
+	if c.expired(rec.FetchedAt, c.cfg.wordTTL) {
+		return rae.WordEntry{}, false
+	}
+	return rec.Entry, true
+}
+
+func (c *diskCache) PutWord(word string, entry rae.WordEntry) {
+	key := cacheKey(word)
+	_ = c.write(cacheWordsDir, key, wordRecord{Key: key, FetchedAt: c.now(), Entry: entry})
+}
+
+func (c *diskCache) GetSearch(terms string) ([]rae.SearchResult, bool) {
+	key := cacheKey(terms)
+
+	var rec searchRecord
+	if err := c.read(cacheSearchDir, key, &rec); err != nil || rec.Key != key {
+		return nil, false
+	}
+	if c.expired(rec.FetchedAt, c.cfg.searchTTL) {
+		return nil, false
+	}
+	return rec.Results, true
+}
+
+func (c *diskCache) PutSearch(terms string, results []rae.SearchResult) {
+	key := cacheKey(terms)
+	_ = c.write(cacheSearchDir, key, searchRecord{Key: key, FetchedAt: c.now(), Results: results})
+}
+
+func (c *diskCache) expired(fetchedAt time.Time, ttl time.Duration) bool {
+	return ttl > 0 && c.now().Sub(fetchedAt) > ttl
+}
+
+type cacheFile struct {
+	path    string
+	kind    string
+	size    int64
+	modTime time.Time
+}
+
+func (c *diskCache) files() ([]cacheFile, error) {
+	var files []cacheFile
+
+	for _, kind := range []string{cacheWordsDir, cacheSearchDir} {
+		dir := filepath.Join(c.cfg.dir, kind)
+		entries, err := os.ReadDir(dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			files = append(files, cacheFile{
+				path:    filepath.Join(dir, entry.Name()),
+				kind:    kind,
+				size:    info.Size(),
+				modTime: info.ModTime(),
+			})
+		}
+	}
+
+	return files, nil
+}
+
+type cacheStats struct {
+	Dir       string
+	Words     int
+	Searches  int
+	Size      int64
+	MaxSize   int64
+	Oldest    time.Time
+	Newest    time.Time
+	WordTTL   time.Duration
+	SearchTTL time.Duration
+}
+
+func (c *diskCache) Stats() (cacheStats, error) {
+	stats := cacheStats{
+		Dir:       c.cfg.dir,
+		MaxSize:   c.cfg.maxSize,
+		WordTTL:   c.cfg.wordTTL,
+		SearchTTL: c.cfg.searchTTL,
+	}
+
+	files, err := c.files()
+	if err != nil {
+		return stats, err
+	}
+
+	for _, f := range files {
+		switch f.kind {
+		case cacheWordsDir:
+			stats.Words++
+		case cacheSearchDir:
+			stats.Searches++
+		}
+		stats.Size += f.size
+		if stats.Oldest.IsZero() || f.modTime.Before(stats.Oldest) {
+			stats.Oldest = f.modTime
+		}
+		if f.modTime.After(stats.Newest) {
+			stats.Newest = f.modTime
+		}
+	}
+
+	return stats, nil
+}
+
+// Clear removes every cached lookup and returns how many were deleted
+func (c *diskCache) Clear() (int, error) {
+	files, err := c.files()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, f := range files {
+		if err := os.Remove(f.path); err == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// Prune removes expired lookups and then the oldest ones until the cache fits
+// in cfg.maxSize. It returns how many files were deleted
+func (c *diskCache) Prune() (int, error) {
+	removed, _, err := c.prune(c.cfg.maxSize)
+	return removed, err
+}
+
+// prune removes expired lookups and then the oldest ones until the cache fits
+// in target bytes, if cfg.maxSize bounds it. It returns how many files were
+// deleted and the size left
+func (c *diskCache) prune(target int64) (int, int64, error) {
+	files, err := c.files()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	removed := 0
+	kept := files[:0]
+	var size int64
+	for _, f := range files {
+		ttl := c.cfg.wordTTL
+		if f.kind == cacheSearchDir {
+			ttl = c.cfg.searchTTL
+		}
+		if c.expired(f.modTime, ttl) {
+			if err := os.Remove(f.path); err == nil {
+				removed++
+				continue
+			}
+		}
+		kept = append(kept, f)
+		size += f.size
+	}
+
+	if c.cfg.maxSize <= 0 || size <= target {
+		return removed, size, nil
+	}
+
+	sort.Slice(kept, func(i, j int) bool { return kept[i].modTime.Before(kept[j].modTime) })
+	for _, f := range kept {
+		if size <= target {
+			break
+		}
+		if err := os.Remove(f.path); err == nil {
+			removed++
+			size -= f.size
+		}
+	}
+
+	return removed, size, nil
+}
+
+// Export writes every cached WordEntry to w as JSON lines, regardless of TTL,
+// and returns how many entries were written
+func (c *diskCache) Export(w io.Writer) (int, error) {
+	files, err := c.files()
+	if err != nil {
+		return 0, err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	exported := 0
+	for _, f := range files {
+		if f.kind != cacheWordsDir {
+			continue
+		}
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			continue
+		}
+		var rec wordRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			continue
+		}
+		if err := enc.Encode(rec); err != nil {
+			return exported, err
+		}
+		exported++
+	}
+
+	return exported, nil
+}
+
+// cachedClient serves lookups from the disk cache and falls through to next
+// on misses. Only successful lookups are cached: suggestions for unknown words
+// may change as the dictionary grows
+type cachedClient struct {
+	next  dictionary
+	cache *diskCache
+}
+
+func newCachedClient(next dictionary, cache *diskCache) *cachedClient {
+	return &cachedClient{next: next, cache: cache}
+}
+
+// cacheHitKey is the context key of the flag raised when a word is served
+// from the disk cache
+type cacheHitKey struct{}
+
+// withCacheHitMark returns ctx carrying a flag raised when a word looked up
+// with it comes from the disk cache rather than from the API
+func withCacheHitMark(ctx context.Context) (context.Context, *atomic.Bool) {
+	mark := new(atomic.Bool)
+	return context.WithValue(ctx, cacheHitKey{}, mark), mark
+}
+
+func (c *cachedClient) Word(ctx context.Context, word string) (rae.WordEntry, error) {
+	if entry, ok := c.cache.GetWord(word); ok {
+		if mark, ok := ctx.Value(cacheHitKey{}).(*atomic.Bool); ok {
+			mark.Store(true)
+		}
+		return entry, nil
+	}
+
+	entry, err := c.next.Word(ctx, word)
+	if err != nil {
+		return entry, err
+	}
+
+	c.cache.PutWord(word, entry)
+	return entry, nil
+}
+
+func (c *cachedClient) Search(ctx context.Context, terms string) ([]rae.SearchResult, error) {
+	if results, ok := c.cache.GetSearch(terms); ok {
+		return results, nil
+	}
+
+	results, err := c.next.Search(ctx, terms)
+	if err != nil {
+		return results, err
+	}
+
+	if len(results) > 0 {
+		c.cache.PutSearch(terms, results)
+	}
+	return results, nil
+}
+
+func runCacheCommand(cache *diskCache, params []string) int {
+	if len(params) == 0 {
+		params = []string{"stats"}
+	}
+
+	switch params[0] {
+	case "stats":
+		stats, err := cache.Stats()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+			return exitError
+		}
+		fmt.Printf(tr("Directorio:    %s\n"), stats.Dir)
+		fmt.Printf(tr("Palabras:      %d\n"), stats.Words)
+		fmt.Printf(tr("Búsquedas:     %d\n"), stats.Searches)
+		fmt.Printf(tr("Tamaño:        %s / %s\n"), formatBytes(stats.Size), formatBytes(stats.MaxSize))
+		fmt.Printf(tr("TTL palabra:   %s\n"), stats.WordTTL)
+		fmt.Printf(tr("TTL búsqueda:  %s\n"), stats.SearchTTL)
+		if !stats.Oldest.IsZero() {
+			fmt.Printf(tr("Más antigua:   %s\n"), stats.Oldest.Format(time.DateTime))
+			fmt.Printf(tr("Más reciente:  %s\n"), stats.Newest.Format(time.DateTime))
+		}
+		return exitFound
+
+	case "clear":
+		removed, err := cache.Clear()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+			return exitError
+		}
+		fmt.Printf(tr("Eliminadas %d entradas de la caché\n"), removed)
+		return exitFound
+
+	case "prune":
+		removed, err := cache.Prune()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+			return exitError
+		}
+		fmt.Printf(tr("Eliminadas %d entradas caducadas o sobrantes\n"), removed)
+		return exitFound
+
+	case "export":
+		w := io.Writer(os.Stdout)
+		if len(params) > 1 {
+			f, err := os.Create(params[1])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+				return exitError
+			}
+			defer f.Close()
+			w = f
+		}
+		exported, err := cache.Export(w)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+			return exitError
+		}
+		fmt.Fprintf(os.Stderr, tr("Exportadas %d palabras\n"), exported)
+		return exitFound
+
+	default:
+		return usageFailure("cache", "subcomando de caché desconocido: %q", params[0])
+	}
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	rae "github.com/rae-api-com/go-rae"
+)
+
+// newTestCache returns a cache in a temporary directory holding the words
+// w00, w01…, the first one the oldest, and the size of each of them
+func newTestCache(t *testing.T, words int, maxSize func(size int64) int64) (*diskCache, int64) {
+	t.Helper()
+
+	now := time.Date(2024, time.March, 9, 8, 0, 0, 0, time.UTC)
+	c := newDiskCache(cacheConfig{dir: t.TempDir()})
+	c.now = func() time.Time { return now }
+
+	var size int64
+	for i := range words {
+		word := fmt.Sprintf("w%02d", i)
+		c.PutWord(word, rae.WordEntry{Word: word})
+		path := c.path(cacheWordsDir, word)
+		modTime := now.Add(time.Duration(i-words) * time.Minute)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		size = info.Size()
+	}
+
+	c.cfg.maxSize = maxSize(size)
+	return c, size
+}
+
+func cachedWords(c *diskCache) []string {
+	var words []string
+	for i := range 100 {
+		if word := fmt.Sprintf("w%02d", i); fileExists(c.path(cacheWordsDir, word)) {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestDiskCachePrune(t *testing.T) {
+	c, size := newTestCache(t, 10, func(size int64) int64 { return 7 * size })
+
+	removed, err := c.Prune()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 3 {
+		t.Errorf("removed = %d, want 3", removed)
+	}
+	if words := cachedWords(c); len(words) != 7 || words[0] != "w03" {
+		t.Errorf("cached = %q, want w03 to w09", words)
+	}
+
+	stats, err := c.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Size != 7*size {
+		t.Errorf("size = %d, want %d", stats.Size, 7*size)
+	}
+}
+
+func TestDiskCacheGrowPrunesToLowWater(t *testing.T) {
+	// Ten words fill the cache, so the eleventh one goes over it
+	c, size := newTestCache(t, 10, func(size int64) int64 { return 10 * size })
+
+	c.PutWord("w10", rae.WordEntry{Word: "w10"})
+	words := cachedWords(c)
+	if len(words) != 9 || words[0] != "w02" {
+		t.Fatalf("cached = %q, want w02 to w10", words)
+	}
+	if c.size != 9*size {
+		t.Errorf("size estimate = %d, want %d", c.size, 9*size)
+	}
+
+	// Below the limit again, the next write prunes nothing
+	c.PutWord("w11", rae.WordEntry{Word: "w11"})
+	if words := cachedWords(c); len(words) != 10 {
+		t.Errorf("cached = %q, want w02 to w11", words)
+	}
+	if c.size != 10*size {
+		t.Errorf("size estimate = %d, want %d", c.size, 10*size)
+	}
+}
@@ -0,0 +1,670 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// command is a subcommand of rae-tui
+type command struct {
+	name string
+	// synopsis describes the positional arguments, e.g. "WORD"
+	synopsis string
+	summary  string
+	// details is printed by "rae-tui help NAME" after the summary
+	details string
+
+	// minArgs and maxArgs bound the positional arguments, maxArgs < 0
+	// meaning any number of them
+	minArgs, maxArgs int
+
+	// subcommands are offered by shell completion for the first argument
+	subcommands []string
+
+	// formats are the output formats supported besides text
+	formats []outputFormat
+
+	// flags registers the options specific to the command
+	flags func(fs *flag.FlagSet, opts *options)
+
+	// standalone commands run without loading the configuration, so they
+	// receive a nil environment
+	standalone bool
+	run        func(env *environment, inv invocation) int
+}
+
+// options are the flags given on the command line. The global ones are
+// accepted anywhere, before or after the command
+type options struct {
+	format  string
+	config  string
+	sets    listFlag
+	noCache bool
+	offline bool
+	help    bool
+	version bool
+
+	// Command specific
+	tags    listFlag
+	note    string
+	limit   int
+	moods   listFlag
+	tenses  listFlag
+	persons listFlag
+	jobs    int
+	rate    float64
+	output  string
+	resume  bool
+	listen  string
+	source  string
+	date    string
+	stats   bool
+}
+
+// invocation is a parsed command line
+type invocation struct {
+	cmd  *command
+	args []string
+	opts options
+}
+
+// listFlag collects the values of a repeated flag
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// commands is populated in init as the help command lists them all
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{
+			name:     "lookup",
+			synopsis: "WORD",
+			summary:  "Look up a word and print its definitions (default command)",
+			details: `Prints the entry of WORD. When WORD does not exist the suggestions or
+fuzzy search matches are offered to choose from, unless --format json is
+given, which reports them without prompting.
+
+Exit codes:
+  0 found, 1 error, 2 only suggestions available, 3 not found`,
+			minArgs: 1,
+			maxArgs: 1,
+			formats: []outputFormat{formatJSON},
+			run:     runLookup,
+		},
+		{
+			name:     "tui",
+			synopsis: "[WORD]",
+			summary:  "Open the interactive interface, optionally looking up WORD",
+			maxArgs:  1,
+			run:      runTUI,
+		},
+		{
+			name:     "search",
+			synopsis: "TEXT...",
+			summary:  "List the fuzzy search matches of TEXT",
+			minArgs:  1,
+			maxArgs:  -1,
+			flags: func(fs *flag.FlagSet, opts *options) {
+				fs.IntVar(&opts.limit, "limit", 0, "list at most `N` matches (0 lists all)")
+			},
+			formats: []outputFormat{formatJSON},
+			run:     runSearch,
+		},
+		{
+			name:     "conjugate",
+			synopsis: "VERB",
+			summary:  "Print the conjugation tables of a verb",
+			details: `Prints every mood and tense of VERB, or those selected with --mood, --tense
+and --person. They take English keys or Spanish names, repeated or comma
+separated, e.g. --mood subjuntivo --tense presente,imperfect --person yo.
+
+Moods:   non_personal, indicative, subjunctive, imperative
+Tenses:  present, present_perfect, imperfect, past_perfect, preterite,
+         past_anterior, future, future_perfect, conditional, conditional_perfect
+Persons: yo, tu, usted, el, nosotros, vosotros, ustedes, ellos
+
+--format json prints the tables as a document and --format csv as one
+verb,mood,tense,person,form row per form.`,
+			minArgs: 1,
+			maxArgs: 1,
+			flags: func(fs *flag.FlagSet, opts *options) {
+				fs.Var(&opts.moods, "mood", "only print the `MOOD`, repeatable or comma separated")
+				fs.Var(&opts.tenses, "tense", "only print the `TENSE`, repeatable or comma separated")
+				fs.Var(&opts.persons, "person", "only print the `PERSON`, repeatable or comma separated")
+			},
+			formats: []outputFormat{formatJSON, formatCSV},
+			run:     runConjugate,
+		},
+		{
+			name:     "compare",
+			synopsis: "WORD WORD",
+			summary:  "Compare two words side by side",
+			details: `Prints the entries of both words in two columns, followed by what they
+have in common: the synonyms both list, their categories and the
+definitions sharing most of their words, marked with ≈ and the numbers of
+the definitions of the other word.
+
+Exit codes:
+  0 both found, 1 error, 2 only suggestions available, 3 not found`,
+			minArgs: 2,
+			maxArgs: 2,
+			formats: []outputFormat{formatJSON},
+			run:     runCompare,
+		},
+		{
+			name:     "batch",
+			synopsis: "[FILE|-]",
+			summary:  "Look up every word of a list, one per line",
+			details: `Reads the words from FILE, or the standard input when FILE is - or missing,
+skipping blank lines and # comments, and looks them up in parallel.
+
+The results are written in input order as Markdown (the text format), or
+one JSON document per line with --format jsonl, or one row per word with
+--format csv. A report of the words not found, with their suggestions, is
+printed to the standard error.
+
+Words that failed, e.g. because the API could not be reached, are not
+written. Run again with --resume and the same --output to look up only the
+words missing from it, after an interruption or errors.
+
+Exit codes:
+  0 all found, 1 errors or interrupted, 3 some words not found`,
+			maxArgs: 1,
+			flags: func(fs *flag.FlagSet, opts *options) {
+				fs.IntVar(&opts.jobs, "jobs", defaultBatchJobs, fmt.Sprintf("look up `N` words at once (default %d)", defaultBatchJobs))
+				fs.Float64Var(&opts.rate, "rate", defaultBatchRate, fmt.Sprintf("start at most `N` API requests per second, 0 disables the limit (default %g)", defaultBatchRate))
+				fs.StringVar(&opts.output, "output", "", "write the results to `FILE` instead of the standard output")
+				fs.StringVar(&opts.output, "o", "", "write the results to `FILE` instead of the standard output")
+				fs.BoolVar(&opts.resume, "resume", false, "skip the words already in --output and append to it")
+			},
+			formats: []outputFormat{formatJSONL, formatCSV, formatMarkdown},
+			run:     runBatch,
+		},
+		{
+			name:    "random",
+			summary: "Look up a random word",
+			details: `Picks a word from the bundled word list, the history or the favorites,
+as chosen with --source or wotd.source, and prints its entry.`,
+			flags: func(fs *flag.FlagSet, opts *options) {
+				fs.StringVar(&opts.source, "source", "", "pick from `SOURCE`: seed, history or favorites (default wotd.source)")
+			},
+			formats: []outputFormat{formatJSON},
+			run:     runRandom,
+		},
+		{
+			name:    "wotd",
+			summary: "Look up the word of the day",
+			details: `Picks the word of the day from the bundled word list, the history or the
+favorites, as chosen with --source or wotd.source, and prints its entry.
+The word only changes with the date, or when the words of the source do,
+so every run on the same day shows the same one.`,
+			flags: func(fs *flag.FlagSet, opts *options) {
+				fs.StringVar(&opts.source, "source", "", "pick from `SOURCE`: seed, history or favorites (default wotd.source)")
+				fs.StringVar(&opts.date, "date", "", "show the word of `YYYY-MM-DD` instead of today")
+			},
+			formats: []outputFormat{formatJSON},
+			run:     runWotd,
+		},
+		{
+			name:    "study",
+			summary: "Review flashcards of your words, or show your progress",
+			details: `Opens the study page of the interactive interface, reviewing the cards due
+with a spaced repetition schedule (SM-2). Cards are made from the favorites,
+the history or the bundled word list, as chosen with --source or
+study.source, and the progress is kept in the data directory.
+
+Each card shows a word; Space reveals its definitions and 0 to 5 grades how
+well you remembered it, 3 or more counting as remembered.
+
+--stats prints a summary of the progress instead.`,
+			flags: func(fs *flag.FlagSet, opts *options) {
+				fs.BoolVar(&opts.stats, "stats", false, "print a summary of the progress")
+				fs.StringVar(&opts.source, "source", "", "make cards from `SOURCE`: favorites, history or seed (default study.source)")
+			},
+			formats: []outputFormat{formatJSON},
+			run:     runStudy,
+		},
+		{
+			name:     "export",
+			synopsis: "[WORD...]",
+			summary:  "Export entries to Anki, Markdown or HTML",
+			details: `Looks up the WORDs, or the words of --source when none is given, and
+exports their entries with definitions, examples, synonyms, antonyms,
+locutions and conjugations:
+
+  markdown  Markdown notes, one section per word (the text format)
+  anki      a tab separated file for File > Import in Anki, the word on the
+            front, its definition on the back, tagged rae-tui and with the
+            tags of the favorite
+  html      a standalone HTML page
+
+Favorites can be narrowed down with --tag, repeatable or comma separated, to
+the ones having any of the tags. Words not found or that failed are reported
+to the standard error and left out.
+
+Exit codes:
+  0 all exported, 1 errors or interrupted, 3 some words not found`,
+			maxArgs: -1,
+			flags: func(fs *flag.FlagSet, opts *options) {
+				fs.StringVar(&opts.source, "source", "", "export the words of `SOURCE` when none is given: favorites, history or seed (default favorites)")
+				fs.Var(&opts.tags, "tag", "only export the favorites with `TAG`, repeatable or comma separated")
+				fs.StringVar(&opts.output, "output", "", "write the export to `FILE` instead of the standard output")
+				fs.StringVar(&opts.output, "o", "", "write the export to `FILE` instead of the standard output")
+				fs.IntVar(&opts.jobs, "jobs", defaultBatchJobs, fmt.Sprintf("look up `N` words at once (default %d)", defaultBatchJobs))
+				fs.Float64Var(&opts.rate, "rate", defaultBatchRate, fmt.Sprintf("start at most `N` API requests per second, 0 disables the limit (default %g)", defaultBatchRate))
+			},
+			formats: []outputFormat{formatMarkdown, formatAnki, formatHTML},
+			run:     runExport,
+		},
+		{
+			name:    "serve",
+			summary: "Serve lookups as a JSON API over HTTP",
+			details: `Listens on server.listen, or --listen, until interrupted, going through the
+same cache and offline dictionary as the other commands.
+
+Endpoints:
+  GET /word/{word}        the lookup of word, as with lookup --format json
+  GET /search?q=TEXT      the fuzzy search matches, limit=N keeps the first N
+  GET /conjugate/{verb}   the conjugation tables, filtered by the repeatable
+                          mood, tense and person parameters
+  GET /health             the status and version of the server
+
+Found words answer 200, unknown ones 404 with their suggestions, and API
+failures 502. Every request is logged to the standard error.`,
+			flags: func(fs *flag.FlagSet, opts *options) {
+				fs.StringVar(&opts.listen, "listen", "", "listen on `HOST:PORT` (default server.listen)")
+			},
+			run: runServe,
+		},
+		{
+			name:    "history",
+			summary: "List the recently looked up words",
+			flags: func(fs *flag.FlagSet, opts *options) {
+				fs.IntVar(&opts.limit, "limit", 0, "list at most `N` words (default ui.history_size)")
+			},
+			formats: []outputFormat{formatJSON},
+			run:     runHistory,
+		},
+		{
+			name:        "favorites",
+			synopsis:    "[list|add WORD|remove WORD|export [FILE]]",
+			summary:     "Manage the favorite words",
+			subcommands: []string{"list", "add", "remove", "export"},
+			details: `Subcommands:
+  list           List the favorites, most recent first (default)
+  add WORD       Bookmark WORD, updating its tags and note if given
+  remove WORD    Remove WORD from the favorites
+  export [FILE]  Write the favorites as CSV, or JSON with --format json
+
+list and export only include the favorites having any of the --tag given.`,
+			maxArgs: 2,
+			flags: func(fs *flag.FlagSet, opts *options) {
+				fs.Var(&opts.tags, "tag", "`TAG` of the favorite, repeatable or comma separated")
+				fs.StringVar(&opts.note, "note", "", "personal `NOTE` about the favorite")
+			},
+			formats: []outputFormat{formatJSON, formatCSV},
+			run:     runFavorites,
+		},
+		{
+			name:        "cache",
+			synopsis:    "[stats|clear|prune|export [FILE]]",
+			summary:     "Manage the lookup cache",
+			subcommands: []string{"stats", "clear", "prune", "export"},
+			details: `Subcommands:
+  stats          Show the size and age of the cache (default)
+  clear          Remove every cached lookup
+  prune          Remove the expired entries and trim the cache to its size limit
+  export [FILE]  Write the cached words as JSON lines`,
+			maxArgs: 2,
+			run:     runCache,
+		},
+		{
+			name:        "offline",
+			synopsis:    "[stats|import [FILE|-]|export [FILE]]",
+			summary:     "Manage the offline dictionary",
+			subcommands: []string{"stats", "import", "export"},
+			details: `Subcommands:
+  stats               Show the number of words available offline (default)
+  import [FILE|-]     Merge a JSON lines export, or the cache when no FILE is given
+  export [FILE]       Write the offline dictionary as JSON lines`,
+			maxArgs: 2,
+			run:     runOffline,
+		},
+		{
+			name:        "config",
+			synopsis:    "[show|validate|path|keys]",
+			summary:     "Show the effective configuration",
+			subcommands: []string{"show", "validate", "path", "keys"},
+			details: `Subcommands:
+  show      Print the configuration after applying the file, environment and flags (default)
+  validate  Check the configuration file
+  path      Print the path of the configuration file
+  keys      List the configuration keys and their environment variables
+
+ui.theme sets the colors of the TUI and the plain output: dark, light,
+high-contrast, monochrome or a theme of your own under [themes.NAME], which
+takes the keys of ui.colors and a base theme for the colors left out:
+
+  [themes.solarized]
+  base = "light"
+  accent = "#b58900"
+
+NO_COLOR turns colors off, and the plain output has none when the standard
+output is not a terminal.`,
+			maxArgs: 1,
+			run:     runConfig,
+		},
+		{
+			name:        "completion",
+			synopsis:    "bash|zsh|fish",
+			summary:     "Print the shell completion script",
+			subcommands: []string{"bash", "zsh", "fish"},
+			details: `Load it in the current shell with, for example:
+  source <(rae-tui completion bash)
+  source <(rae-tui completion zsh)
+  rae-tui completion fish | source`,
+			minArgs:    1,
+			maxArgs:    1,
+			standalone: true,
+			run:        runCompletion,
+		},
+		{
+			name:       "help",
+			synopsis:   "[COMMAND]",
+			summary:    "Show the help of rae-tui or of COMMAND",
+			maxArgs:    1,
+			standalone: true,
+			run:        runHelp,
+		},
+		{
+			name:       "version",
+			summary:    "Show version information",
+			standalone: true,
+			run:        runVersion,
+		},
+	}
+
+	// help completes the command names
+	findCommand("help").subcommands = commandNames()
+}
+
+func findCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// newFlagSet registers the global options, plus those of cmd if any. The
+// current values of opts are kept as defaults, so options given before the
+// command survive parsing the rest of the command line
+func newFlagSet(cmd *command, opts *options) *flag.FlagSet {
+	name := "rae-tui"
+	if cmd != nil {
+		name += " " + cmd.name
+	}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {}
+
+	fs.StringVar(&opts.format, "format", opts.format, "output `FORMAT`: text (default), json, jsonl, csv or markdown")
+	fs.StringVar(&opts.format, "f", opts.format, "output `FORMAT`: text (default), json, jsonl, csv or markdown")
+	fs.StringVar(&opts.config, "config", opts.config, "read the configuration from `FILE`")
+	fs.StringVar(&opts.config, "c", opts.config, "read the configuration from `FILE`")
+	fs.Var(&opts.sets, "set", "override a configuration `KEY=VALUE`, e.g. --set api.timeout=10s")
+	fs.BoolVar(&opts.noCache, "no-cache", opts.noCache, "always query the API, bypassing the on-disk cache")
+	fs.BoolVar(&opts.offline, "offline", opts.offline, "serve lookups only from the offline dictionary")
+	fs.BoolVar(&opts.help, "help", opts.help, "show this help")
+	fs.BoolVar(&opts.help, "h", opts.help, "show this help")
+	fs.BoolVar(&opts.version, "version", opts.version, "show version information")
+	fs.BoolVar(&opts.version, "v", opts.version, "show version information")
+
+	if cmd != nil && cmd.flags != nil {
+		cmd.flags(fs, opts)
+	}
+	return fs
+}
+
+// parseArgs parses the command line arguments, without the program name.
+// Without a command the first argument is the word to look up, and without
+// any argument the TUI is opened. On errors inv.cmd is the command whose
+// arguments were wrong, if it was known
+func parseArgs(argv []string) (invocation, error) {
+	var inv invocation
+
+	// Global options may come before the command
+	global := newFlagSet(nil, &inv.opts)
+	if err := global.Parse(argv); err != nil {
+		return inv, err
+	}
+	rest := global.Args()
+
+	switch {
+	case len(rest) == 0 && (inv.opts.help || inv.opts.version):
+		inv.cmd = findCommand("help")
+		if inv.opts.version {
+			inv.cmd = findCommand("version")
+		}
+		return inv, nil
+	case len(rest) == 0:
+		inv.cmd = findCommand("tui")
+		return inv, nil
+	case len(argv) > len(rest) && argv[len(argv)-len(rest)-1] == "--":
+		// "rae-tui -- WORD" looks up WORD even if it names a command
+		inv.cmd = findCommand("lookup")
+	default:
+		if inv.cmd = findCommand(rest[0]); inv.cmd != nil {
+			rest = rest[1:]
+		} else {
+			inv.cmd = findCommand("lookup")
+		}
+	}
+
+	args, err := parseInterleaved(newFlagSet(inv.cmd, &inv.opts), rest)
+	if err != nil {
+		return inv, err
+	}
+
+	switch {
+	case inv.opts.help:
+		inv.args = []string{inv.cmd.name}
+		inv.cmd = findCommand("help")
+		return inv, nil
+	case inv.opts.version:
+		inv.cmd = findCommand("version")
+		return inv, nil
+	}
+
+	if len(args) < inv.cmd.minArgs || (inv.cmd.maxArgs >= 0 && len(args) > inv.cmd.maxArgs) {
+		return inv, fmt.Errorf(tr("argumentos no válidos\nUso: rae-tui %s"), usageLine(inv.cmd))
+	}
+	inv.args = args
+
+	if inv.opts.format != "" {
+		format, err := parseOutputFormat(inv.opts.format)
+		if err != nil {
+			return inv, err
+		}
+		if format != formatText && !slices.Contains(inv.cmd.formats, format) {
+			return inv, fmt.Errorf(tr("%s no admite el formato %s"), inv.cmd.name, format)
+		}
+	}
+	return inv, nil
+}
+
+// parseInterleaved parses flags anywhere in args, not only before the first
+// positional argument, and returns the positional ones. Everything after a
+// "--" terminator is positional
+func parseInterleaved(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		if len(rest) < len(args) && args[len(args)-len(rest)-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// usageFailure reports a misuse of the command name and returns exitError
+func usageFailure(name, format string, a ...any) int {
+	fmt.Fprintf(os.Stderr, "rae-tui: "+tr(format)+"\n", a...)
+	fmt.Fprintf(os.Stderr, "Run \"%s\" for usage\n", strings.TrimSpace("rae-tui help "+name))
+	return exitError
+}
+
+func usageLine(cmd *command) string {
+	if cmd.synopsis == "" {
+		return cmd.name
+	}
+	return cmd.name + " " + cmd.synopsis
+}
+
+// flagEntry is a flag and its aliases, as shown in help and completions
+type flagEntry struct {
+	Short, Long string
+	Arg, Usage  string
+}
+
+func (e flagEntry) names() string {
+	names := "--" + e.Long
+	if e.Short != "" {
+		names = "-" + e.Short + ", " + names
+	}
+	if e.Arg != "" {
+		names += " " + e.Arg
+	}
+	return names
+}
+
+// flagEntries groups the flags of fs with their one letter aliases, which are
+// registered with the same usage
+func flagEntries(fs *flag.FlagSet) []flagEntry {
+	var entries []flagEntry
+	byUsage := make(map[string]int)
+	var shorts []*flag.Flag
+
+	fs.VisitAll(func(f *flag.Flag) {
+		if len(f.Name) == 1 {
+			shorts = append(shorts, f)
+			return
+		}
+		arg, usage := flag.UnquoteUsage(f)
+		if _, ok := f.Value.(interface{ IsBoolFlag() bool }); ok {
+			arg = ""
+		}
+		byUsage[f.Usage] = len(entries)
+		entries = append(entries, flagEntry{Long: f.Name, Arg: arg, Usage: usage})
+	})
+	for _, f := range shorts {
+		if i, ok := byUsage[f.Usage]; ok {
+			entries[i].Short = f.Name
+		}
+	}
+	return entries
+}
+
+// commandFlags returns the options specific to cmd
+func commandFlags(cmd *command) []flagEntry {
+	if cmd.flags == nil {
+		return nil
+	}
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	cmd.flags(fs, &options{})
+	return flagEntries(fs)
+}
+
+func printFlags(w io.Writer, entries []flagEntry) {
+	for _, e := range entries {
+		fmt.Fprintf(w, "  %-26s %s\n", e.names(), e.Usage)
+	}
+}
+
+func printHelp(w io.Writer) {
+	fmt.Fprintln(w, "RAE Dictionary CLI")
+	fmt.Fprintln(w, "\nUsage:")
+	fmt.Fprintln(w, "  rae-tui [OPTIONS] COMMAND [ARGS]")
+	fmt.Fprintln(w, "  rae-tui [OPTIONS] WORD     - Same as \"rae-tui lookup WORD\"")
+	fmt.Fprintln(w, "  rae-tui [OPTIONS]          - Same as \"rae-tui tui\"")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-12s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w, "\nOptions:")
+	printFlags(w, flagEntries(newFlagSet(nil, &options{})))
+	fmt.Fprintln(w, "\nThe configuration is read from $RAE_TUI_CONFIG or $XDG_CONFIG_HOME/rae-tui/config.toml")
+	fmt.Fprintln(w, "\nExamples:")
+	fmt.Fprintln(w, "  rae-tui hola          - Show definition of 'hola' in CLI mode")
+	fmt.Fprintln(w, "  rae-tui tui           - Open TUI interface")
+	fmt.Fprintln(w, "  rae-tui tui casa      - Open TUI interface and search for 'casa'")
+	fmt.Fprintln(w, "  rae-tui -f json hola  - Print the entry of 'hola' as JSON")
+	fmt.Fprintln(w, "  rae-tui search cas    - List the words matching 'cas'")
+	fmt.Fprintln(w, "\nRun \"rae-tui help COMMAND\" for more information on a command.")
+}
+
+func printCommandHelp(w io.Writer, cmd *command) {
+	fmt.Fprintf(w, "Usage: rae-tui %s\n\n", usageLine(cmd))
+	fmt.Fprintln(w, cmd.summary)
+	if cmd.details != "" {
+		fmt.Fprintf(w, "\n%s\n", cmd.details)
+	}
+
+	if entries := commandFlags(cmd); len(entries) > 0 {
+		fmt.Fprintln(w, "\nOptions:")
+		printFlags(w, entries)
+	}
+	fmt.Fprintln(w, "\nGlobal options are listed by \"rae-tui help\".")
+}
+
+func runHelp(_ *environment, inv invocation) int {
+	if len(inv.args) == 0 {
+		printHelp(os.Stdout)
+		return exitFound
+	}
+
+	cmd := findCommand(inv.args[0])
+	if cmd == nil {
+		return usageFailure("", "comando desconocido: %q", inv.args[0])
+	}
+	printCommandHelp(os.Stdout, cmd)
+	return exitFound
+}
+
+func runVersion(_ *environment, _ invocation) int {
+	fmt.Printf("rae-tui %s\n", version)
+	fmt.Printf("commit: %s\n", commit)
+	fmt.Printf("built: %s\n", buildTime)
+	return exitFound
+}
+
+// commandNames returns the names of the commands, sorted
+func commandNames() []string {
+	names := make([]string, 0, len(commands))
+	for _, cmd := range commands {
+		names = append(names, cmd.name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		argv    []string
+		cmd     string
+		args    []string
+		format  string
+		offline bool
+		limit   int
+		moods   []string
+		// err is a substring of the expected error, if any
+		err string
+	}{
+		{name: "no arguments opens the TUI", argv: nil, cmd: "tui"},
+		{name: "a word is looked up", argv: []string{"casa"}, cmd: "lookup", args: []string{"casa"}},
+		{name: "explicit command", argv: []string{"lookup", "casa"}, cmd: "lookup", args: []string{"casa"}},
+		{name: "separator looks up a command name", argv: []string{"--", "history"}, cmd: "lookup", args: []string{"history"}},
+		{name: "separator after the command", argv: []string{"lookup", "--", "-casa"}, cmd: "lookup", args: []string{"-casa"}},
+		{
+			name: "flags after the positional argument",
+			argv: []string{"conjugate", "tener", "--mood", "indicative"},
+			cmd:  "conjugate", args: []string{"tener"}, moods: []string{"indicative"},
+		},
+		{
+			name: "flags before the positional argument",
+			argv: []string{"conjugate", "--mood", "indicative", "tener", "--mood", "subjunctive"},
+			cmd:  "conjugate", args: []string{"tener"}, moods: []string{"indicative", "subjunctive"},
+		},
+		{
+			name: "flags between positional arguments",
+			argv: []string{"search", "de", "--limit", "5", "prisa"},
+			cmd:  "search", args: []string{"de", "prisa"}, limit: 5,
+		},
+		{
+			name: "global flags before the command",
+			argv: []string{"--offline", "--format", "json", "history"},
+			cmd:  "history", format: "json", offline: true,
+		},
+		{
+			name: "global flags before a word",
+			argv: []string{"-f", "json", "casa"},
+			cmd:  "lookup", args: []string{"casa"}, format: "json",
+		},
+		{name: "global flags after the command", argv: []string{"history", "--offline"}, cmd: "history", offline: true},
+		{name: "missing argument", argv: []string{"conjugate"}, cmd: "conjugate", err: "argumentos no válidos"},
+		{name: "too many arguments", argv: []string{"lookup", "casa", "perro"}, cmd: "lookup", err: "argumentos no válidos"},
+		{name: "arguments to a command taking none", argv: []string{"history", "casa"}, cmd: "history", err: "argumentos no válidos"},
+		{name: "format not supported by the command", argv: []string{"history", "--format", "csv"}, cmd: "history", err: "history no admite el formato csv"},
+		{name: "format not supported by lookup", argv: []string{"--format", "anki", "casa"}, cmd: "lookup", err: "lookup no admite el formato anki"},
+		{name: "unknown format", argv: []string{"--format", "xml", "casa"}, cmd: "lookup", err: "xml"},
+		{name: "unknown global flag", argv: []string{"--bogus"}, err: "bogus"},
+		{name: "unknown command flag", argv: []string{"history", "--mood", "indicative"}, cmd: "history", err: "mood"},
+		{name: "help", argv: []string{"--help"}, cmd: "help"},
+		{name: "help of a command", argv: []string{"conjugate", "-h"}, cmd: "help", args: []string{"conjugate"}},
+		{name: "help skips the argument count", argv: []string{"conjugate", "--help"}, cmd: "help", args: []string{"conjugate"}},
+		{name: "help command", argv: []string{"help", "export"}, cmd: "help", args: []string{"export"}},
+		{name: "version", argv: []string{"-v"}, cmd: "version"},
+		{name: "version after a word", argv: []string{"casa", "--version"}, cmd: "version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv, err := parseArgs(tt.argv)
+
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var name string
+			if inv.cmd != nil {
+				name = inv.cmd.name
+			}
+			if name != tt.cmd {
+				t.Errorf("command = %q, want %q", name, tt.cmd)
+			}
+			if tt.err != "" {
+				return
+			}
+
+			if !slices.Equal(inv.args, tt.args) {
+				t.Errorf("args = %q, want %q", inv.args, tt.args)
+			}
+			if inv.opts.format != tt.format {
+				t.Errorf("format = %q, want %q", inv.opts.format, tt.format)
+			}
+			if inv.opts.offline != tt.offline {
+				t.Errorf("offline = %v, want %v", inv.opts.offline, tt.offline)
+			}
+			if inv.opts.limit != tt.limit {
+				t.Errorf("limit = %d, want %d", inv.opts.limit, tt.limit)
+			}
+			if !slices.Equal(inv.opts.moods, tt.moods) {
+				t.Errorf("moods = %q, want %q", inv.opts.moods, tt.moods)
+			}
+		})
+	}
+}
+
+func TestParseInterleaved(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		positional []string
+		verbose    bool
+		output     string
+		wantErr    bool
+	}{
+		{name: "no arguments", args: nil, positional: nil},
+		{name: "only positional", args: []string{"a", "b"}, positional: []string{"a", "b"}},
+		{name: "flags first", args: []string{"-v", "-o", "x", "a"}, positional: []string{"a"}, verbose: true, output: "x"},
+		{name: "flags last", args: []string{"a", "-o", "x", "-v"}, positional: []string{"a"}, verbose: true, output: "x"},
+		{name: "flags between", args: []string{"a", "-o=x", "b"}, positional: []string{"a", "b"}, output: "x"},
+		{name: "separator", args: []string{"a", "--", "-v", "b"}, positional: []string{"a", "-v", "b"}},
+		{name: "separator first", args: []string{"--", "-o", "x"}, positional: []string{"-o", "x"}},
+		{name: "flags before the separator", args: []string{"-v", "--", "-o"}, positional: []string{"-o"}, verbose: true},
+		{name: "unknown flag", args: []string{"a", "-x"}, wantErr: true},
+		{name: "missing value", args: []string{"a", "-o"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			verbose := fs.Bool("v", false, "")
+			output := fs.String("o", "", "")
+
+			positional, err := parseInterleaved(fs, tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("no error, positional = %q", positional)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !slices.Equal(positional, tt.positional) {
+				t.Errorf("positional = %q, want %q", positional, tt.positional)
+			}
+			if *verbose != tt.verbose {
+				t.Errorf("-v = %v, want %v", *verbose, tt.verbose)
+			}
+			if *output != tt.output {
+				t.Errorf("-o = %q, want %q", *output, tt.output)
+			}
+		})
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	tests := []struct {
+		status lookupStatus
+		code   int
+	}{
+		{statusFound, 0},
+		{statusError, 1},
+		{statusSuggested, 2},
+		{statusNotFound, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			if code := tt.status.exitCode(); code != tt.code {
+				t.Errorf("exit code = %d, want %d", code, tt.code)
+			}
+		})
+	}
+
+	stderr := os.Stderr
+	os.Stderr, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer func() { os.Stderr = stderr }()
+	if code := usageFailure("lookup", "argumentos no válidos"); code != exitError {
+		t.Errorf("usage failure exit code = %d, want %d", code, exitError)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	rae "github.com/rae-api-com/go-rae"
+)
+
+// clipboardCommand is a tool copying its input to the system clipboard, used
+// when env names the display it needs, if any
+type clipboardCommand struct {
+	env  string
+	args []string
+}
+
+// clipboardCommands are tried in order, Wayland first
+var clipboardCommands = []clipboardCommand{
+	{"WAYLAND_DISPLAY", []string{"wl-copy"}},
+	{"DISPLAY", []string{"xclip", "-selection", "clipboard"}},
+	{"DISPLAY", []string{"xsel", "--clipboard", "--input"}},
+	{"", []string{"pbcopy"}},
+}
+
+// osc52 returns the escape sequence asking the terminal to put text in the
+// clipboard, which works over SSH. Inside tmux it is wrapped so that tmux
+// passes it on to the terminal
+func osc52(text string) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\x07"
+	if os.Getenv("TMUX") != "" {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	return seq
+}
+
+// copyWithCommand copies text with the first clipboard tool installed whose
+// display is set, returning its name, or "" when there is none. The tool is
+// killed if ctx ends first
+func copyWithCommand(ctx context.Context, text string) (string, error) {
+	for _, c := range clipboardCommands {
+		if c.env != "" && os.Getenv(c.env) == "" {
+			continue
+		}
+		path, err := exec.LookPath(c.args[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.CommandContext(ctx, path, c.args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return c.args[0], fmt.Errorf("%s: %w", c.args[0], err)
+		}
+		return c.args[0], nil
+	}
+	return "", nil
+}
+
+var plainConjugationStyle = conjugationStyle{
+	mood:   func(s string) string { return s },
+	tense:  func(s string) string { return s },
+	person: func(s string) string { return s },
+}
+
+// entryText lays out entry as plain text, as the results show it
+func entryText(entry rae.WordEntry) string {
+	var b strings.Builder
+	view := newExportView(entry)
+	b.WriteString(view.Word + "\n")
+
+	for _, meaning := range view.Meanings {
+		if meaning.Title != "" {
+			fmt.Fprintf(&b, "\n%s\n", meaning.Title)
+		}
+		if meaning.Origin != "" {
+			fmt.Fprintf(&b, tr("\nOrigen: %s\n"), meaning.Origin)
+		}
+		if len(meaning.Definitions) > 0 {
+			b.WriteString("\n")
+		}
+		for _, def := range meaning.Definitions {
+			fmt.Fprintf(&b, "%s\n", def.Raw)
+			for _, ex := range def.Examples {
+				fmt.Fprintf(&b, "    %s\n", ex)
+			}
+			if len(def.SynonymsV2) > 0 {
+				fmt.Fprintf(&b, tr("    Sin.: %s\n"), plainRelatedWords(def.SynonymsV2))
+			}
+			if len(def.AntonymsV2) > 0 {
+				fmt.Fprintf(&b, tr("    Ant.: %s\n"), plainRelatedWords(def.AntonymsV2))
+			}
+		}
+		if len(meaning.Locutions) > 0 {
+			b.WriteString(tr("\nLocuciones\n"))
+			for _, loc := range meaning.Locutions {
+				fmt.Fprintf(&b, "  %s\n", loc.Expression)
+				for _, sense := range loc.Senses {
+					fmt.Fprintf(&b, "    %s\n", sense.Raw)
+				}
+			}
+		}
+	}
+
+	if conjugations := entryConjugations(entry); conjugations != nil {
+		b.WriteString(tr("\nConjugación\n"))
+		renderConjugationTables(&b, conjugationTables(conjugations, conjugationFilter{}), plainConjugationStyle, "  ")
+	}
+	return b.String()
+}
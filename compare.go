@@ -0,0 +1,492 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	rae "github.com/rae-api-com/go-rae"
+	"golang.org/x/term"
+)
+
+const (
+	// overlapThreshold is the least similarity of two definitions, as the
+	// Dice coefficient of their content words, to count as overlapping
+	overlapThreshold = 0.3
+	// summaryOverlaps is how many overlapping definitions the summary lists
+	summaryOverlaps = 5
+	// defaultCompareWidth is used when the output is not a terminal
+	defaultCompareWidth = 100
+)
+
+// senseNumber matches the number opening a definition, e.g. "2. "
+var senseNumber = regexp.MustCompile(`^\s*\d+\.\s*`)
+
+// compareStopwords are left out of the content words of definitions: the
+// most frequent function words and the usage abbreviations found mid-text
+var compareStopwords = map[string]bool{
+	"al": true, "algo": true, "alguien": true, "alguno": true, "cada": true, "como": true,
+	"con": true, "cual": true, "de": true, "del": true, "el": true, "ella": true, "en": true,
+	"entre": true, "es": true, "esta": true, "este": true, "la": true, "las": true, "le": true,
+	"lo": true, "los": true, "mas": true, "muy": true, "no": true, "otra": true, "otro": true,
+	"para": true, "pero": true, "por": true, "que": true, "se": true, "sin": true, "sobre": true,
+	"su": true, "sus": true, "un": true, "una": true, "uno": true, "ya": true,
+	"coloq": true, "desus": true, "intr": true, "prnl": true, "tr": true, "us": true,
+}
+
+var categoryLabels = map[rae.WordCategory]string{
+	rae.CategoryArticle:      "artículo",
+	rae.CategoryNoun:         "sustantivo",
+	rae.CategoryPronoun:      "pronombre",
+	rae.CategoryAdjective:    "adjetivo",
+	rae.CategoryVerb:         "verbo",
+	rae.CategoryAdverb:       "adverbio",
+	rae.CategoryPreposition:  "preposición",
+	rae.CategoryConjunction:  "conjunción",
+	rae.CategoryInterjection: "interjección",
+}
+
+func categoryLabel(c rae.WordCategory) string {
+	if label, ok := categoryLabels[c]; ok {
+		return label
+	}
+	return string(c)
+}
+
+// definitionOverlap pairs two definitions sharing content words. Left and
+// Right number the definitions of each entry from 1, across its meanings
+type definitionOverlap struct {
+	Left       int      `json:"left"`
+	Right      int      `json:"right"`
+	Similarity float64  `json:"similarity"`
+	Words      []string `json:"shared_words"`
+}
+
+type categoryDiff struct {
+	Shared    []string `json:"shared,omitempty"`
+	LeftOnly  []string `json:"left_only,omitempty"`
+	RightOnly []string `json:"right_only,omitempty"`
+}
+
+// wordComparison is what two entries have in common and where they differ
+type wordComparison struct {
+	SharedSynonyms []string `json:"shared_synonyms,omitempty"`
+	// LeftListsRight is set when the right word is a synonym of the left one
+	LeftListsRight bool                `json:"left_lists_right,omitempty"`
+	RightListsLeft bool                `json:"right_lists_left,omitempty"`
+	Categories     categoryDiff        `json:"categories"`
+	Overlaps       []definitionOverlap `json:"overlaps,omitempty"`
+}
+
+// entrySenses flattens the definitions of every meaning of entry
+func entrySenses(entry rae.WordEntry) []rae.Definition {
+	var senses []rae.Definition
+	for _, meaning := range entry.Meanings {
+		senses = append(senses, meaning.Definitions...)
+	}
+	return senses
+}
+
+// contentStem folds word for comparison: no accents, no plural and no final
+// e, so that "lugar" and "lugares" or "clase" and "clases" match
+func contentStem(word string) string {
+	word = strings.TrimSuffix(foldName(word), "s")
+	if utf8.RuneCountInString(word) > 4 {
+		word = strings.TrimSuffix(word, "e")
+	}
+	return word
+}
+
+// contentWords returns the stems of the meaningful words of a definition,
+// each mapped to the first form it was found as
+func contentWords(raw string) map[string]string {
+	words := make(map[string]string)
+	for _, word := range definitionWords(raw) {
+		if compareStopwords[foldName(word)] || utf8.RuneCountInString(word) < 3 {
+			continue
+		}
+		if stem := contentStem(word); words[stem] == "" {
+			words[stem] = word
+		}
+	}
+	return words
+}
+
+// compareEntries finds the synonyms, categories and definitions left and
+// right have in common
+func compareEntries(left, right rae.WordEntry) wordComparison {
+	var cmp wordComparison
+	leftSenses, rightSenses := entrySenses(left), entrySenses(right)
+
+	rightSynonyms := make(map[string]bool)
+	for _, def := range rightSenses {
+		for _, syn := range def.SynonymsV2 {
+			rightSynonyms[cacheKey(syn.Word)] = true
+			cmp.RightListsLeft = cmp.RightListsLeft || cacheKey(syn.Word) == cacheKey(left.Word)
+		}
+	}
+	for _, def := range leftSenses {
+		for _, syn := range def.SynonymsV2 {
+			cmp.LeftListsRight = cmp.LeftListsRight || cacheKey(syn.Word) == cacheKey(right.Word)
+			if rightSynonyms[cacheKey(syn.Word)] {
+				cmp.SharedSynonyms = append(cmp.SharedSynonyms, syn.Word)
+			}
+		}
+	}
+	cmp.SharedSynonyms = uniqueWords(cmp.SharedSynonyms)
+
+	leftCategories, rightCategories := senseCategories(leftSenses), senseCategories(rightSenses)
+	for _, c := range leftCategories {
+		if slices.Contains(rightCategories, c) {
+			cmp.Categories.Shared = append(cmp.Categories.Shared, c)
+		} else {
+			cmp.Categories.LeftOnly = append(cmp.Categories.LeftOnly, c)
+		}
+	}
+	for _, c := range rightCategories {
+		if !slices.Contains(leftCategories, c) {
+			cmp.Categories.RightOnly = append(cmp.Categories.RightOnly, c)
+		}
+	}
+
+	rightWords := make([]map[string]string, len(rightSenses))
+	for j, def := range rightSenses {
+		rightWords[j] = contentWords(def.Raw)
+	}
+	for i, def := range leftSenses {
+		words := contentWords(def.Raw)
+		for j, other := range rightWords {
+			var shared []string
+			for stem, word := range words {
+				if _, ok := other[stem]; ok {
+					shared = append(shared, word)
+				}
+			}
+			if len(shared) == 0 {
+				continue
+			}
+			similarity := 2 * float64(len(shared)) / float64(len(words)+len(other))
+			if similarity < overlapThreshold {
+				continue
+			}
+			sort.Strings(shared)
+			cmp.Overlaps = append(cmp.Overlaps, definitionOverlap{
+				Left:       i + 1,
+				Right:      j + 1,
+				Similarity: math.Round(similarity*100) / 100,
+				Words:      shared,
+			})
+		}
+	}
+	sort.SliceStable(cmp.Overlaps, func(i, j int) bool {
+		return cmp.Overlaps[i].Similarity > cmp.Overlaps[j].Similarity
+	})
+	return cmp
+}
+
+// senseCategories lists the categories of senses in order of appearance
+func senseCategories(senses []rae.Definition) []string {
+	var categories []string
+	for _, def := range senses {
+		if def.Category == "" {
+			continue
+		}
+		if label := categoryLabel(def.Category); !slices.Contains(categories, label) {
+			categories = append(categories, label)
+		}
+	}
+	return categories
+}
+
+// overlapsOf returns, for every definition of one side, the numbers of the
+// definitions of the other side it overlaps with
+func (c wordComparison) overlapsOf(left bool) map[int][]int {
+	overlaps := make(map[int][]int)
+	for _, o := range c.Overlaps {
+		if left {
+			overlaps[o.Left] = append(overlaps[o.Left], o.Right)
+		} else {
+			overlaps[o.Right] = append(overlaps[o.Right], o.Left)
+		}
+	}
+	for _, numbers := range overlaps {
+		slices.Sort(numbers)
+	}
+	return overlaps
+}
+
+// compareStyle is the emphasis of a line of a comparison, rendered as ANSI
+// escapes by the CLI and as color tags by the TUI
+type compareStyle int
+
+const (
+	compareNormal compareStyle = iota
+	compareTitle
+	compareHeading
+	compareMuted
+	// compareShared marks what both words have in common
+	compareShared
+	// compareDiffers marks what only one of the words has
+	compareDiffers
+)
+
+// compareLine is a paragraph of a comparison, wrapped by whoever renders it.
+// Lines of definitions are wrapped with a hanging indent
+type compareLine struct {
+	text   string
+	style  compareStyle
+	indent int
+}
+
+// comparisonSide lays out one of the entries, marking what it shares with
+// and how it differs from the other
+func comparisonSide(entry rae.WordEntry, other string, cmp wordComparison, left bool) []compareLine {
+	lines := []compareLine{{text: entry.Word, style: compareTitle}}
+
+	categories, only := cmp.Categories.Shared, cmp.Categories.LeftOnly
+	if !left {
+		only = cmp.Categories.RightOnly
+	}
+	if len(categories)+len(only) > 0 {
+		text := tr("Categorías: ") + strings.Join(append(slices.Clone(categories), only...), ", ")
+		style := compareMuted
+		if len(only) > 0 {
+			text += trf(" (solo aquí: %s)", strings.Join(only, ", "))
+			style = compareDiffers
+		}
+		lines = append(lines, compareLine{text: text, style: style})
+	}
+	lines = append(lines, compareLine{})
+
+	overlaps := cmp.overlapsOf(left)
+	var synonyms []string
+	for i, def := range entrySenses(entry) {
+		n := i + 1
+		line := compareLine{text: fmt.Sprintf("%d. %s", n, senseNumber.ReplaceAllString(def.Raw, "")), indent: 3}
+		if numbers := overlaps[n]; len(numbers) > 0 {
+			refs := make([]string, len(numbers))
+			for k, number := range numbers {
+				refs[k] = fmt.Sprint(number)
+			}
+			line.text += fmt.Sprintf("  ≈ %s %s", other, strings.Join(refs, ", "))
+			line.style = compareShared
+		}
+		lines = append(lines, line)
+		synonyms = append(synonyms, relatedWords(def.SynonymsV2)...)
+	}
+
+	var shared, own []string
+	for _, syn := range uniqueWords(synonyms) {
+		if slices.ContainsFunc(cmp.SharedSynonyms, func(s string) bool { return cacheKey(s) == cacheKey(syn) }) {
+			shared = append(shared, syn)
+		} else {
+			own = append(own, syn)
+		}
+	}
+	if len(shared)+len(own) > 0 {
+		lines = append(lines, compareLine{})
+	}
+	if len(shared) > 0 {
+		lines = append(lines, compareLine{text: tr("Sin. en común: ") + strings.Join(shared, ", "), style: compareShared})
+	}
+	if len(own) > 0 {
+		lines = append(lines, compareLine{text: tr("Sin.: ") + strings.Join(own, ", ")})
+	}
+	return lines
+}
+
+// comparisonSummary lists what the two words have in common
+func comparisonSummary(left, right string, cmp wordComparison) []compareLine {
+	lines := []compareLine{{text: tr("En común"), style: compareHeading}}
+
+	switch {
+	case cmp.LeftListsRight && cmp.RightListsLeft:
+		lines = append(lines, compareLine{text: trf("«%s» y «%s» se citan como sinónimos", left, right), style: compareShared})
+	case cmp.LeftListsRight:
+		lines = append(lines, compareLine{text: trf("«%s» figura entre los sinónimos de «%s»", right, left), style: compareShared})
+	case cmp.RightListsLeft:
+		lines = append(lines, compareLine{text: trf("«%s» figura entre los sinónimos de «%s»", left, right), style: compareShared})
+	}
+
+	if len(cmp.SharedSynonyms) > 0 {
+		lines = append(lines, compareLine{text: tr("Sinónimos: ") + strings.Join(cmp.SharedSynonyms, ", "), style: compareShared, indent: 2})
+	} else {
+		lines = append(lines, compareLine{text: tr("Sinónimos: ninguno"), style: compareMuted})
+	}
+
+	if len(cmp.Categories.Shared) > 0 {
+		lines = append(lines, compareLine{text: tr("Categorías: ") + strings.Join(cmp.Categories.Shared, ", "), indent: 2})
+	}
+	if len(cmp.Categories.LeftOnly)+len(cmp.Categories.RightOnly) > 0 {
+		var parts []string
+		if len(cmp.Categories.LeftOnly) > 0 {
+			parts = append(parts, trf("solo %s: %s", left, strings.Join(cmp.Categories.LeftOnly, ", ")))
+		}
+		if len(cmp.Categories.RightOnly) > 0 {
+			parts = append(parts, trf("solo %s: %s", right, strings.Join(cmp.Categories.RightOnly, ", ")))
+		}
+		lines = append(lines, compareLine{text: tr("Categorías distintas: ") + strings.Join(parts, "; "), style: compareDiffers, indent: 2})
+	}
+
+	if len(cmp.Overlaps) == 0 {
+		return append(lines, compareLine{text: tr("Definiciones parecidas: ninguna"), style: compareMuted})
+	}
+	lines = append(lines, compareLine{text: tr("Definiciones parecidas:")})
+	for i, o := range cmp.Overlaps {
+		if i == summaryOverlaps {
+			lines = append(lines, compareLine{text: trf("  y %d más", len(cmp.Overlaps)-i), style: compareMuted})
+			break
+		}
+		lines = append(lines, compareLine{
+			text:   fmt.Sprintf("  %s %d ≈ %s %d (%s)", left, o.Left, right, o.Right, strings.Join(o.Words, ", ")),
+			style:  compareShared,
+			indent: 4,
+		})
+	}
+	return lines
+}
+
+// jsonComparison is the document written by `compare --format json`. The
+// comparison is only set when both words were found
+type jsonComparison struct {
+	SchemaVersion int             `json:"schema_version"`
+	Status        lookupStatus    `json:"status"`
+	Left          jsonLookup      `json:"left"`
+	Right         jsonLookup      `json:"right"`
+	Comparison    *wordComparison `json:"comparison,omitempty"`
+}
+
+// statusRank orders the statuses from best to worst
+var statusRank = map[lookupStatus]int{
+	statusFound:     0,
+	statusSuggested: 1,
+	statusNotFound:  2,
+	statusError:     3,
+}
+
+// compareJSON looks both words up at once and compares their entries
+func compareJSON(ctx context.Context, cli dictionary, a, b string) jsonComparison {
+	doc := jsonComparison{SchemaVersion: jsonSchemaVersion}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		doc.Right = lookupJSON(ctx, cli, b)
+	}()
+	doc.Left = lookupJSON(ctx, cli, a)
+	<-done
+
+	doc.Status = doc.Left.Status
+	if statusRank[doc.Right.Status] > statusRank[doc.Status] {
+		doc.Status = doc.Right.Status
+	}
+	if doc.Status == statusFound {
+		cmp := compareEntries(*doc.Left.Entry, *doc.Right.Entry)
+		doc.Comparison = &cmp
+	}
+	return doc
+}
+
+// ansiCompareStyles returns the escapes of each style, read from the theme
+// when rendering as it is only set at startup
+func ansiCompareStyles() map[compareStyle]string {
+	return map[compareStyle]string{
+		compareTitle:   Bold + Title,
+		compareHeading: Bold,
+		compareMuted:   Muted,
+		compareShared:  Accent,
+		compareDiffers: Info,
+	}
+}
+
+// wrapText breaks text into lines of at most width runes, the first one
+// indented by nothing and the rest by indent
+func wrapText(text string, width, indent int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		switch {
+		case line == "":
+			line = word
+		case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = strings.Repeat(" ", indent) + word
+		}
+	}
+	return append(lines, line)
+}
+
+// styledLines wraps lines to width and colors them with ANSI escapes,
+// returning the colored lines and their visible widths
+func styledLines(lines []compareLine, width int) (out []string, widths []int) {
+	styles := ansiCompareStyles()
+	for _, line := range lines {
+		for _, text := range wrapText(line.text, width, line.indent) {
+			widths = append(widths, utf8.RuneCountInString(text))
+			if style := styles[line.style]; style != "" && text != "" {
+				text = style + text + Reset
+			}
+			out = append(out, text)
+		}
+	}
+	return out, widths
+}
+
+// terminalWidth is the width of the terminal on the standard output
+func terminalWidth() int {
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+		return width
+	}
+	return defaultCompareWidth
+}
+
+// renderComparison writes the entries of doc side by side in columns fitting
+// width, followed by what they have in common
+func renderComparison(w io.Writer, doc jsonComparison, width int) {
+	columnWidth := max((width-3)/2, 20)
+	left, leftWidths := styledLines(comparisonSide(*doc.Left.Entry, doc.Right.Entry.Word, *doc.Comparison, true), columnWidth)
+	right, _ := styledLines(comparisonSide(*doc.Right.Entry, doc.Left.Entry.Word, *doc.Comparison, false), columnWidth)
+
+	for i := range max(len(left), len(right)) {
+		line, pad := "", columnWidth
+		if i < len(left) {
+			line, pad = left[i], columnWidth-leftWidths[i]
+		}
+		line += strings.Repeat(" ", max(pad, 0)) + " │ "
+		if i < len(right) {
+			line += right[i]
+		}
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+
+	fmt.Fprintln(w)
+	summary, _ := styledLines(comparisonSummary(doc.Left.Entry.Word, doc.Right.Entry.Word, *doc.Comparison), max(width, 40))
+	for _, line := range summary {
+		fmt.Fprintln(w, line)
+	}
+}
+
+// describeMissing explains why a compared word has no entry
+func describeMissing(doc jsonLookup) string {
+	suggestions := doc.Suggestions
+	for _, hit := range doc.Results {
+		suggestions = append(suggestions, hit.Word)
+	}
+	switch {
+	case doc.Status == statusError:
+		return trf("no se pudo buscar «%s»: %s", doc.Query, doc.Error)
+	case len(suggestions) > 0:
+		return trf("no se encontró «%s»; ¿quisiste decir: %s?", doc.Query, strings.Join(suggestions, ", "))
+	default:
+		return trf("no se encontró «%s»", doc.Query)
+	}
+}
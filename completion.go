@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// completionPrograms are the names rae-tui is installed as: the Makefile
+// installs the binary as "rae"
+var completionPrograms = []string{"rae-tui", "rae"}
+
+type completionCommand struct {
+	Name, Summary string
+	Subcommands   []string
+	Flags         []flagEntry
+}
+
+type completionData struct {
+	Programs []string
+	Commands []completionCommand
+	Global   []flagEntry
+	// ValueFlags are the flags taking a separate value, as a shell pattern
+	ValueFlags string
+}
+
+func newCompletionData() completionData {
+	data := completionData{
+		Programs: completionPrograms,
+		Global:   flagEntries(newFlagSet(nil, &options{})),
+	}
+
+	values := make(map[string]struct{})
+	addValues := func(entries []flagEntry) {
+		for _, e := range entries {
+			if e.Arg == "" {
+				continue
+			}
+			for _, name := range flagNames(e) {
+				if _, ok := values[name]; !ok {
+					values[name] = struct{}{}
+					data.ValueFlags += "|" + name
+				}
+			}
+		}
+	}
+	addValues(data.Global)
+
+	for _, cmd := range commands {
+		c := completionCommand{Name: cmd.name, Summary: cmd.summary, Subcommands: cmd.subcommands}
+		c.Flags = commandFlags(cmd)
+		addValues(c.Flags)
+		data.Commands = append(data.Commands, c)
+	}
+	data.ValueFlags = strings.TrimPrefix(data.ValueFlags, "|")
+	return data
+}
+
+func flagNames(e flagEntry) []string {
+	names := []string{"--" + e.Long}
+	if e.Short != "" {
+		names = append([]string{"-" + e.Short}, names...)
+	}
+	return names
+}
+
+// flagWords returns every name of the given flags, separated by spaces
+func flagWords(entries []flagEntry) string {
+	var words []string
+	for _, e := range entries {
+		words = append(words, flagNames(e)...)
+	}
+	return strings.Join(words, " ")
+}
+
+var completionFuncs = template.FuncMap{
+	"join":      strings.Join,
+	"flagWords": flagWords,
+	"quote": func(s string) string {
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	},
+}
+
+var bashCompletion = template.Must(template.New("bash").Funcs(completionFuncs).Parse(`# bash completion for rae-tui
+_rae_tui() {
+    local cur="${COMP_WORDS[COMP_CWORD]}" prev="${COMP_WORDS[COMP_CWORD-1]}" cmd="" i
+
+    case "$prev" in
+        -f|--format) COMPREPLY=($(compgen -W "text json jsonl csv markdown anki html" -- "$cur")); return ;;
+        -c|--config) COMPREPLY=($(compgen -f -- "$cur")); return ;;
+        {{.ValueFlags}}) return ;;
+    esac
+
+    for ((i = 1; i < COMP_CWORD; i++)); do
+        case "${COMP_WORDS[i]}" in
+            {{.ValueFlags}}) ((i++)) ;;
+            -*) ;;
+            *) cmd="${COMP_WORDS[i]}"; break ;;
+        esac
+    done
+
+    local words
+    case "$cmd" in
+        "") words="{{range .Commands}}{{.Name}} {{end}}{{flagWords .Global}}" ;;
+{{- range .Commands}}
+        {{.Name}}) words="{{join .Subcommands " "}} {{flagWords .Flags}} {{flagWords $.Global}}" ;;
+{{- end}}
+        *) words="{{flagWords .Global}}" ;;
+    esac
+    COMPREPLY=($(compgen -W "$words" -- "$cur"))
+}
+complete -F _rae_tui{{range .Programs}} {{.}}{{end}}
+`))
+
+var zshCompletion = template.Must(template.New("zsh").Funcs(completionFuncs).Parse(`#compdef{{range .Programs}} {{.}}{{end}}
+
+_rae_tui() {
+    local -a commands
+    commands=(
+{{- range .Commands}}
+        {{quote (printf "%s:%s" .Name .Summary)}}
+{{- end}}
+    )
+
+    case "${words[CURRENT-1]}" in
+        -f|--format) compadd text json jsonl csv markdown anki html; return ;;
+        -c|--config) _files; return ;;
+        {{.ValueFlags}}) return ;;
+    esac
+
+    local cmd="" i
+    for ((i = 2; i < CURRENT; i++)); do
+        case "${words[i]}" in
+            {{.ValueFlags}}) ((i++)) ;;
+            -*) ;;
+            *) cmd="${words[i]}"; break ;;
+        esac
+    done
+
+    case "$cmd" in
+        "") _describe 'command' commands; compadd -- {{flagWords .Global}} ;;
+{{- range .Commands}}
+        {{.Name}}) compadd -- {{join .Subcommands " "}} {{flagWords .Flags}} {{flagWords $.Global}} ;;
+{{- end}}
+        *) compadd -- {{flagWords .Global}} ;;
+    esac
+}
+
+compdef _rae_tui{{range .Programs}} {{.}}{{end}}
+`))
+
+var fishCompletion = template.Must(template.New("fish").Funcs(completionFuncs).Parse(`# fish completion for rae-tui
+for prog in{{range .Programs}} {{.}}{{end}}
+    complete -c $prog -f
+{{- range .Commands}}
+    complete -c $prog -n __fish_use_subcommand -a {{.Name}} -d {{quote .Summary}}
+{{- if .Subcommands}}
+    complete -c $prog -n '__fish_seen_subcommand_from {{.Name}}' -a {{quote (join .Subcommands " ")}}
+{{- end}}
+{{- $cmd := .Name}}
+{{- range .Flags}}
+    complete -c $prog -n '__fish_seen_subcommand_from {{$cmd}}'{{if .Short}} -s {{.Short}}{{end}} -l {{.Long}}{{if .Arg}} -x{{end}} -d {{quote .Usage}}
+{{- end}}
+{{- end}}
+{{- range .Global}}
+    complete -c $prog{{if .Short}} -s {{.Short}}{{end}} -l {{.Long}}{{if eq .Long "format"}} -x -a 'text json jsonl csv markdown anki html'{{else if eq .Long "config"}} -r -F{{else if .Arg}} -x{{end}} -d {{quote .Usage}}
+{{- end}}
+end
+`))
+
+func runCompletion(_ *environment, inv invocation) int {
+	var tmpl *template.Template
+	switch inv.args[0] {
+	case "bash":
+		tmpl = bashCompletion
+	case "zsh":
+		tmpl = zshCompletion
+	case "fish":
+		tmpl = fishCompletion
+	default:
+		return usageFailure("completion", "shell no soportada: %q", inv.args[0])
+	}
+
+	if err := tmpl.Execute(os.Stdout, newCompletionData()); err != nil {
+		fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+		return exitError
+	}
+	return exitFound
+}
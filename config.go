@@ -0,0 +1,555 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/gdamore/tcell/v2"
+)
+
+// envPrefix prefixes the environment variables overriding config keys, e.g.
+// RAE_TUI_API_TIMEOUT overrides api.timeout
+const envPrefix = "RAE_TUI_"
+
+// Config is the effective configuration: defaults, overridden by the config
+// file, then by RAE_TUI_* environment variables, then by command line flags
+type Config struct {
+	// Format is the output format of CLI commands: text, json, jsonl, csv,
+	// markdown, anki or html where supported
+	Format  string        `toml:"format"`
+	Offline bool          `toml:"offline"`
+	API     apiConfig     `toml:"api"`
+	Cache   cacheSettings `toml:"cache"`
+	UI      uiConfig      `toml:"ui"`
+	Server  serverConfig  `toml:"server"`
+	Wotd    wotdConfig    `toml:"wotd"`
+	Study   studyConfig   `toml:"study"`
+	Export  exportConfig  `toml:"export"`
+	// Keys maps TUI actions to the key triggering them, overriding the
+	// preset of ui.keymap
+	Keys map[string]string `toml:"keys"`
+	// Themes are user-defined themes, picked by name with ui.theme
+	Themes map[string]themeConfig `toml:"themes"`
+}
+
+type apiConfig struct {
+	// Version is sent in the User-Agent of API requests
+	Version string        `toml:"version"`
+	Timeout time.Duration `toml:"timeout"`
+}
+
+type cacheSettings struct {
+	Enabled   bool          `toml:"enabled"`
+	Dir       string        `toml:"dir"`
+	WordTTL   time.Duration `toml:"word_ttl"`
+	SearchTTL time.Duration `toml:"search_ttl"`
+	MaxSizeMB int64         `toml:"max_size_mb"`
+}
+
+type uiConfig struct {
+	// PreviewLength truncates definitions in the CLI fuzzy search results
+	PreviewLength int `toml:"preview_length"`
+	// TUIPreviewLength truncates definitions in the TUI fuzzy search results
+	TUIPreviewLength  int           `toml:"tui_preview_length"`
+	HistorySize       int           `toml:"history_size"`
+	AutocompleteDelay time.Duration `toml:"autocomplete_delay"`
+	// Keymap is the preset of TUI key bindings: vim or emacs
+	Keymap string `toml:"keymap"`
+	// Language is the language of the interface: es, en, or auto to follow
+	// LC_ALL, LC_MESSAGES or LANG. Definitions are always in Spanish
+	Language string `toml:"language"`
+	// Mouse enables clicking and scrolling with the wheel in the TUI. The
+	// terminal only selects text with the mouse while it is disabled
+	Mouse bool `toml:"mouse"`
+	// OutlineWidth is the width of the outline beside the results, zero
+	// hiding it
+	OutlineWidth int `toml:"outline_width"`
+	// Theme is the colors of the TUI and the plain output: dark, light,
+	// high-contrast, monochrome or one of [themes]
+	Theme string `toml:"theme"`
+	// Colors override single colors of Theme
+	Colors themeColors `toml:"colors"`
+}
+
+type serverConfig struct {
+	// Listen is the host:port `rae-tui serve` listens on
+	Listen string `toml:"listen"`
+}
+
+type wotdConfig struct {
+	// Source is where random and daily words are picked from: seed,
+	// history or favorites
+	Source string `toml:"source"`
+}
+
+type studyConfig struct {
+	// Source is where flashcards are made from: favorites, history or seed
+	Source string `toml:"source"`
+	// NewCards is the number of new cards in a study session
+	NewCards int `toml:"new_cards"`
+}
+
+type exportConfig struct {
+	// Format is the format the TUI exports entries to: markdown, anki or html
+	Format string `toml:"format"`
+	// Dir is where the TUI writes exported entries, the working directory
+	// when empty
+	Dir string `toml:"dir"`
+}
+
+// defaultKeys are the TUI actions that can be rebound and their key in the
+// vim preset, the default one
+var defaultKeys = map[string]string{
+	"quit":          "q",
+	"down":          "j",
+	"up":            "k",
+	"back":          "h",
+	"forward":       "l",
+	"history":       "H",
+	"favorite":      "s",
+	"edit_favorite": "e",
+	"favorites":     "F",
+	"new_search":    "n",
+	"conjugate":     "c",
+	"word_of_day":   "w",
+	"study":         "S",
+	"export":        "x",
+	"compare":       "v",
+	"find":          "/",
+	"fold_all":      "-",
+	"unfold_all":    "+",
+	"find_next":     "n",
+	"find_previous": "N",
+	"help":          "?",
+	// Paging and jumps inside the entry shown
+	"half_page_down":   "Ctrl-D",
+	"half_page_up":     "Ctrl-U",
+	"top":              "g",
+	"bottom":           "G",
+	"next_sense":       "}",
+	"previous_sense":   "{",
+	"next_homonym":     ")",
+	"previous_homonym": "(",
+	"goto_locutions":   "L",
+	"goto_conjugation": "C",
+	// Copies to the clipboard
+	"copy_definition": "y",
+	"copy_entry":      "Y",
+	"copy_markdown":   "M",
+	"copy_word":       "W",
+}
+
+func defaultConfig() Config {
+	cache := defaultCacheConfig()
+
+	return Config{
+		Format: string(formatText),
+		API: apiConfig{
+			Version: version,
+			Timeout: 5 * time.Second,
+		},
+		Cache: cacheSettings{
+			Enabled:   true,
+			Dir:       cache.dir,
+			WordTTL:   cache.wordTTL,
+			SearchTTL: cache.searchTTL,
+			MaxSizeMB: cache.maxSize >> 20,
+		},
+		UI: uiConfig{
+			PreviewLength:     60,
+			TUIPreviewLength:  70,
+			HistorySize:       historyPageSize,
+			AutocompleteDelay: autocompleteDelay,
+			Keymap:            "vim",
+			Language:          languageAuto,
+			Mouse:             true,
+			OutlineWidth:      24,
+			Theme:             themeDark,
+		},
+		Server: serverConfig{
+			Listen: "127.0.0.1:8080",
+		},
+		Wotd: wotdConfig{
+			Source: wordSourceSeed,
+		},
+		Study: studyConfig{
+			Source:   wordSourceFavorites,
+			NewCards: 20,
+		},
+		Export: exportConfig{
+			Format: string(formatMarkdown),
+		},
+		Keys: make(map[string]string),
+	}
+}
+
+func defaultConfigPath() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "rae-tui", "config.toml")
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "rae-tui", "config.toml")
+}
+
+// loadConfig builds the configuration from the defaults, the file at path,
+// which may be missing unless required, and the environment. Callers apply
+// their own overrides and then call Validate
+func loadConfig(path string, required bool) (Config, error) {
+	cfg := defaultConfig()
+
+	if path != "" {
+		if err := cfg.decodeFile(path); err != nil {
+			if required || !errors.Is(err, fs.ErrNotExist) {
+				return cfg, err
+			}
+		}
+	}
+
+	return cfg, cfg.applyEnv(os.Environ())
+}
+
+func (c *Config) decodeFile(path string) error {
+	md, err := toml.DecodeFile(path, c)
+	if err != nil {
+		var perr toml.ParseError
+		if errors.As(err, &perr) {
+			return fmt.Errorf("%s:%d: %s", path, perr.Position.Line, perr.Message)
+		}
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, key := range undecoded {
+			keys[i] = key.String()
+		}
+		return fmt.Errorf(tr("%s: claves desconocidas: %s"), path, strings.Join(keys, ", "))
+	}
+
+	return nil
+}
+
+// applyEnv applies RAE_TUI_* variables from environ, rejecting unknown ones so
+// typos do not go unnoticed
+func (c *Config) applyEnv(environ []string) error {
+	byEnv := make(map[string]string)
+	for _, key := range c.SettingKeys() {
+		byEnv[envName(key)] = key
+	}
+
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, envPrefix) || name == envPrefix+"CONFIG" {
+			continue
+		}
+		key, known := byEnv[name]
+		if !known {
+			return fmt.Errorf(tr("variable de entorno desconocida: %s"), name)
+		}
+		if err := c.Set(key, value); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func envName(key string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// SettingKeys returns every settable dotted key, e.g. "api.timeout", sorted
+func (c *Config) SettingKeys() []string {
+	var keys []string
+	collectKeys(reflect.TypeOf(*c), "", &keys)
+	for action := range defaultKeys {
+		keys = append(keys, "keys."+action)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func collectKeys(t reflect.Type, prefix string, keys *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("toml")
+		if field.Type.Kind() == reflect.Map {
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct {
+			collectKeys(field.Type, prefix+name+".", keys)
+			continue
+		}
+		*keys = append(*keys, prefix+name)
+	}
+}
+
+// Set assigns value to the dotted key, parsing it according to the key type
+func (c *Config) Set(key, value string) error {
+	if action, ok := strings.CutPrefix(key, "keys."); ok {
+		if _, known := defaultKeys[action]; !known {
+			return fmt.Errorf(tr("acción desconocida: %s"), action)
+		}
+		c.Keys[action] = value
+		return nil
+	}
+
+	v := reflect.ValueOf(c).Elem()
+	for _, part := range strings.Split(key, ".") {
+		if v.Kind() != reflect.Struct {
+			return fmt.Errorf(tr("clave desconocida: %s"), key)
+		}
+		field, ok := fieldByTag(v, part)
+		if !ok {
+			return fmt.Errorf(tr("clave desconocida: %s"), key)
+		}
+		v = field
+	}
+
+	switch {
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf(tr("%s: duración inválida %q"), key, value)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(value)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf(tr("%s: booleano inválido %q"), key, value)
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Int, v.Kind() == reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf(tr("%s: número inválido %q"), key, value)
+		}
+		v.SetInt(n)
+	default:
+		return fmt.Errorf(tr("clave desconocida: %s"), key)
+	}
+
+	return nil
+}
+
+func fieldByTag(v reflect.Value, tag string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("toml") == tag {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// Validate reports every invalid value at once
+func (c *Config) Validate() error {
+	var errs []error
+
+	if _, err := parseOutputFormat(c.Format); err != nil {
+		errs = append(errs, fmt.Errorf("format: %w", err))
+	}
+	if c.API.Timeout <= 0 {
+		errs = append(errs, errors.New(tr("api.timeout debe ser positivo")))
+	}
+	if c.Cache.WordTTL < 0 || c.Cache.SearchTTL < 0 {
+		errs = append(errs, errors.New(tr("cache.word_ttl y cache.search_ttl no pueden ser negativos")))
+	}
+	if c.Cache.MaxSizeMB < 0 {
+		errs = append(errs, errors.New(tr("cache.max_size_mb no puede ser negativo")))
+	}
+	if c.UI.PreviewLength <= 0 || c.UI.TUIPreviewLength <= 0 {
+		errs = append(errs, errors.New(tr("ui.preview_length y ui.tui_preview_length deben ser positivos")))
+	}
+	if c.UI.HistorySize <= 0 {
+		errs = append(errs, errors.New(tr("ui.history_size debe ser positivo")))
+	}
+	if c.UI.AutocompleteDelay < 0 {
+		errs = append(errs, errors.New(tr("ui.autocomplete_delay no puede ser negativo")))
+	}
+	if c.UI.OutlineWidth < 0 {
+		errs = append(errs, errors.New(tr("ui.outline_width no puede ser negativo")))
+	}
+
+	if _, _, err := net.SplitHostPort(c.Server.Listen); err != nil {
+		errs = append(errs, fmt.Errorf(tr("server.listen: dirección inválida %q, usa HOST:PUERTO"), c.Server.Listen))
+	}
+
+	if err := validWordSource(c.Wotd.Source); err != nil {
+		errs = append(errs, fmt.Errorf("wotd.source: %w", err))
+	}
+
+	if err := validWordSource(c.Study.Source); err != nil {
+		errs = append(errs, fmt.Errorf("study.source: %w", err))
+	}
+	if c.Study.NewCards < 0 {
+		errs = append(errs, errors.New(tr("study.new_cards no puede ser negativo")))
+	}
+
+	if err := validExportFormat(outputFormat(c.Export.Format)); err != nil {
+		errs = append(errs, fmt.Errorf("export.format: %w", err))
+	}
+
+	if _, err := resolveTheme(c.UI.Theme, c.Themes); err != nil {
+		errs = append(errs, fmt.Errorf("ui.theme: %w", err))
+	}
+	errs = append(errs, validThemeColors("ui.colors", c.UI.Colors)...)
+	for _, name := range slices.Sorted(maps.Keys(c.Themes)) {
+		if _, builtin := themes[name]; builtin {
+			errs = append(errs, fmt.Errorf(tr("themes.%s: no se puede redefinir un tema incorporado"), name))
+			continue
+		}
+		if _, err := resolveTheme(name, c.Themes); err != nil {
+			errs = append(errs, fmt.Errorf("themes.%s: %w", name, err))
+		}
+		errs = append(errs, validThemeColors("themes."+name, c.Themes[name].themeColors)...)
+	}
+
+	switch c.UI.Language {
+	case languageAuto, languageSpanish, languageEnglish:
+	default:
+		errs = append(errs, fmt.Errorf(tr("ui.language: debe ser auto, es o en, no %q"), c.UI.Language))
+	}
+	if _, ok := keyPresets[c.UI.Keymap]; !ok {
+		errs = append(errs, fmt.Errorf(tr("ui.keymap: debe ser vim o emacs, no %q"), c.UI.Keymap))
+	}
+	for action := range c.Keys {
+		if _, known := defaultKeys[action]; !known {
+			errs = append(errs, fmt.Errorf(tr("keys.%s: acción desconocida"), action))
+		}
+	}
+
+	// Find actions are checked apart, as they may reuse the keys of others
+	type usedKey struct {
+		find    bool
+		binding keyBinding
+	}
+	bindings := c.keyBindings()
+	used := make(map[usedKey]string, len(bindings))
+	for _, action := range slices.Sorted(maps.Keys(bindings)) {
+		key := bindings[action]
+		binding, ok := parseKey(key)
+		if !ok {
+			errs = append(errs, fmt.Errorf(tr("keys.%s: tecla desconocida %q, usa un carácter o un nombre como Ctrl-N"), action, key))
+			continue
+		}
+		if reservedKeys[binding.key] {
+			errs = append(errs, fmt.Errorf(tr("keys.%s: la tecla %s está reservada"), action, binding))
+			continue
+		}
+		uk := usedKey{findActions[action], binding}
+		if other, dup := used[uk]; dup {
+			errs = append(errs, fmt.Errorf(tr("keys.%s: la tecla %q ya está asignada a %s"), action, key, other))
+			continue
+		}
+		used[uk] = action
+	}
+
+	return errors.Join(errs...)
+}
+
+// validThemeColors reports the colors of colors, under the table prefix, that
+// are set but unknown
+func validThemeColors(prefix string, colors themeColors) []error {
+	var errs []error
+	v := reflect.ValueOf(colors)
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Field(i).String()
+		if name != "" && name != colorDefault && !validColor(name) {
+			tag := v.Type().Field(i).Tag.Get("toml")
+			errs = append(errs, fmt.Errorf(tr("%s.%s: color desconocido %q"), prefix, tag, name))
+		}
+	}
+	return errs
+}
+
+func validColor(name string) bool {
+	if strings.HasPrefix(name, "#") {
+		_, err := strconv.ParseUint(name[1:], 16, 32)
+		return len(name) == 7 && err == nil
+	}
+	_, ok := tcell.ColorNames[strings.ToLower(name)]
+	return ok
+}
+
+// themeColors returns the colors of ui.theme with ui.colors over them
+func (c Config) themeColors() themeColors {
+	colors, err := resolveTheme(c.UI.Theme, c.Themes)
+	if err != nil {
+		colors = themes[themeDark]
+	}
+	return colors.over(c.UI.Colors)
+}
+
+func (c Config) cacheConfig() cacheConfig {
+	return cacheConfig{
+		dir:       c.Cache.Dir,
+		wordTTL:   c.Cache.WordTTL,
+		searchTTL: c.Cache.SearchTTL,
+		maxSize:   c.Cache.MaxSizeMB << 20,
+	}
+}
+
+func (c Config) Encode(w io.Writer) error {
+	enc := toml.NewEncoder(w)
+	enc.Indent = ""
+	return enc.Encode(c)
+}
+
+func runConfigCommand(cfg Config, path string, params []string) int {
+	if len(params) == 0 {
+		params = []string{"show"}
+	}
+
+	switch params[0] {
+	case "show":
+		// Show every binding and color, not only those overriding the presets
+		cfg.Keys = cfg.keyBindings()
+		cfg.UI.Colors = cfg.themeColors()
+		if err := cfg.Encode(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+			return exitError
+		}
+		return exitFound
+
+	case "validate":
+		// Invalid configurations are reported by main before getting here
+		if _, err := os.Stat(path); err != nil {
+			fmt.Printf(tr("No existe %s, se usan los valores por defecto\n"), path)
+			return exitFound
+		}
+		fmt.Printf(tr("%s es válido\n"), path)
+		return exitFound
+
+	case "path":
+		fmt.Println(path)
+		return exitFound
+
+	case "keys":
+		for _, key := range cfg.SettingKeys() {
+			fmt.Printf("%-30s %s\n", key, envName(key))
+		}
+		return exitFound
+
+	default:
+		return usageFailure("config", "subcomando de configuración desconocido: %q", params[0])
+	}
+}
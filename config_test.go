@@ -0,0 +1,162 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		// err is a substring of the expected error, empty for a valid config
+		err string
+	}{
+		{name: "defaults", modify: func(c *Config) {}},
+		{name: "unknown format", modify: func(c *Config) { c.Format = "xml" }, err: "format:"},
+		{name: "zero timeout", modify: func(c *Config) { c.API.Timeout = 0 }, err: "api.timeout"},
+		{name: "negative ttl", modify: func(c *Config) { c.Cache.SearchTTL = -time.Hour }, err: "cache.word_ttl"},
+		{name: "negative cache size", modify: func(c *Config) { c.Cache.MaxSizeMB = -1 }, err: "cache.max_size_mb"},
+		{name: "zero preview", modify: func(c *Config) { c.UI.TUIPreviewLength = 0 }, err: "ui.preview_length"},
+		{name: "zero history", modify: func(c *Config) { c.UI.HistorySize = 0 }, err: "ui.history_size"},
+		{name: "hex color", modify: func(c *Config) { c.UI.Colors.HeaderBackground = "#1e90ff" }},
+		{name: "color name in capitals", modify: func(c *Config) { c.UI.Colors.FooterText = "Yellow" }},
+		{name: "unknown color", modify: func(c *Config) { c.UI.Colors.FooterText = "bluish" }, err: "ui.colors.footer_text"},
+		{name: "short hex color", modify: func(c *Config) { c.UI.Colors.SelectedText = "#fff" }, err: "ui.colors.selected_text"},
+		{name: "built-in theme", modify: func(c *Config) { c.UI.Theme = themeHighContrast }},
+		{name: "unknown theme", modify: func(c *Config) { c.UI.Theme = "sepia" }, err: "ui.theme"},
+		{name: "terminal color", modify: func(c *Config) { c.UI.Colors.Background = colorDefault }},
+		{
+			name: "user-defined theme",
+			modify: func(c *Config) {
+				c.Themes = map[string]themeConfig{"solarized": {Base: themeLight, themeColors: themeColors{Accent: "#b58900"}}}
+				c.UI.Theme = "solarized"
+			},
+		},
+		{
+			name:   "redefined built-in theme",
+			modify: func(c *Config) { c.Themes = map[string]themeConfig{themeDark: {}} },
+			err:    "themes.dark: no se puede redefinir",
+		},
+		{
+			name:   "unknown base theme",
+			modify: func(c *Config) { c.Themes = map[string]themeConfig{"solarized": {Base: "sepia"}} },
+			err:    "themes.solarized: tema desconocido",
+		},
+		{
+			name: "unknown color of a theme",
+			modify: func(c *Config) {
+				c.Themes = map[string]themeConfig{"solarized": {themeColors: themeColors{Accent: "ocre"}}}
+			},
+			err: "themes.solarized.accent",
+		},
+		{name: "unknown action", modify: func(c *Config) { c.Keys["jump"] = "x" }, err: "keys.jump: acción desconocida"},
+		{name: "several characters", modify: func(c *Config) { c.Keys["quit"] = "qq" }, err: "keys.quit"},
+		{name: "key bound twice", modify: func(c *Config) { c.Keys["quit"] = "j" }, err: "ya está asignada"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			tt.modify(&cfg)
+
+			err := cfg.Validate()
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error = %v, want one containing %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestConfigSet(t *testing.T) {
+	tests := []struct {
+		key, value string
+		err        string
+	}{
+		{key: "api.timeout", value: "10s"},
+		{key: "api.timeout", value: "10", err: "duración inválida"},
+		{key: "offline", value: "true"},
+		{key: "offline", value: "sí", err: "booleano inválido"},
+		{key: "cache.max_size_mb", value: "50"},
+		{key: "cache.max_size_mb", value: "50MB", err: "número inválido"},
+		{key: "ui.colors.header_text", value: "black"},
+		{key: "keys.quit", value: "x"},
+		{key: "keys.jump", value: "x", err: "acción desconocida"},
+		{key: "api.retries", value: "3", err: "clave desconocida"},
+		{key: "api.timeout.seconds", value: "3", err: "clave desconocida"},
+		{key: "ui", value: "x", err: "clave desconocida"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			cfg := defaultConfig()
+			err := cfg.Set(tt.key, tt.value)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error = %v, want one containing %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	t.Run("file and environment", func(t *testing.T) {
+		t.Setenv(envPrefix+"UI_HISTORY_SIZE", "7")
+		path := write("ok.toml", "format = \"json\"\n[api]\ntimeout = \"9s\"\n")
+
+		cfg, err := loadConfig(path, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Format != "json" || cfg.API.Timeout != 9*time.Second || cfg.UI.HistorySize != 7 {
+			t.Errorf("format = %q, timeout = %v, history size = %d", cfg.Format, cfg.API.Timeout, cfg.UI.HistorySize)
+		}
+	})
+
+	t.Run("unknown keys", func(t *testing.T) {
+		path := write("typo.toml", "[api]\ntimeuot = \"9s\"\n")
+		if _, err := loadConfig(path, true); err == nil || !strings.Contains(err.Error(), "api.timeuot") {
+			t.Fatalf("error = %v, want the unknown key", err)
+		}
+	})
+
+	t.Run("unknown variable", func(t *testing.T) {
+		t.Setenv(envPrefix+"API_TIMEUOT", "9s")
+		if _, err := loadConfig("", false); err == nil || !strings.Contains(err.Error(), envPrefix+"API_TIMEUOT") {
+			t.Fatalf("error = %v, want the unknown variable", err)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		path := filepath.Join(dir, "missing.toml")
+		if _, err := loadConfig(path, false); err != nil {
+			t.Fatalf("optional file: %v", err)
+		}
+		if _, err := loadConfig(path, true); err == nil {
+			t.Fatal("required file: no error")
+		}
+	})
+}
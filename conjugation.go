@@ -0,0 +1,495 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	rae "github.com/rae-api-com/go-rae"
+)
+
+// conjugationForm is a single inflected form and the person it belongs to.
+// Non-personal forms use the name of the form as person, e.g. "gerund"
+type conjugationForm struct {
+	Person string `json:"person"`
+	Form   string `json:"form"`
+
+	label  string
+	plural bool
+}
+
+// conjugationTable is the paradigm of a tense. Non-personal forms and the
+// imperative have no tense
+type conjugationTable struct {
+	Mood  string            `json:"mood"`
+	Tense string            `json:"tense,omitempty"`
+	Forms []conjugationForm `json:"forms"`
+
+	moodLabel, tenseLabel string
+}
+
+type conjugationPerson struct {
+	key, label string
+	plural     bool
+}
+
+var (
+	personYo       = conjugationPerson{"yo", "yo", false}
+	personTu       = conjugationPerson{"tu", "tú", false}
+	personUsted    = conjugationPerson{"usted", "usted", false}
+	personEl       = conjugationPerson{"el", "él, ella", false}
+	personNosotros = conjugationPerson{"nosotros", "nosotros, nosotras", true}
+	personVosotros = conjugationPerson{"vosotros", "vosotros, vosotras", true}
+	personUstedes  = conjugationPerson{"ustedes", "ustedes", true}
+	personEllos    = conjugationPerson{"ellos", "ellos, ellas", true}
+
+	conjugationPersons = []conjugationPerson{
+		personYo, personTu, personUsted, personEl,
+		personNosotros, personVosotros, personUstedes, personEllos,
+	}
+)
+
+func (p conjugationPerson) form(form string) conjugationForm {
+	return conjugationForm{Person: p.key, Form: form, label: p.label, plural: p.plural}
+}
+
+func personForms(c rae.Conjugation) []conjugationForm {
+	return []conjugationForm{
+		personYo.form(c.SingularFirstPerson),
+		personTu.form(c.SingularSecondPerson),
+		personUsted.form(c.SingularFormalSecondPerson),
+		personEl.form(c.SingularThirdPerson),
+		personNosotros.form(c.PluralFirstPerson),
+		personVosotros.form(c.PluralSecondPerson),
+		personUstedes.form(c.PluralFormalSecondPerson),
+		personEllos.form(c.PluralThirdPerson),
+	}
+}
+
+type conjugationTense struct {
+	key, label string
+	forms      func(c *rae.Conjugations) []conjugationForm
+}
+
+type conjugationMood struct {
+	key, label string
+	// aliases are other names accepted by --mood
+	aliases []string
+	tenses  []conjugationTense
+}
+
+// conjugationMoods is the full paradigm, in the order of the RAE tables
+var conjugationMoods = []conjugationMood{
+	{
+		key:     "non_personal",
+		label:   "Formas no personales",
+		aliases: []string{"no personales", "no personal"},
+		tenses: []conjugationTense{{
+			forms: func(c *rae.Conjugations) []conjugationForm {
+				np := c.ConjugationNonPersonal
+				return []conjugationForm{
+					{Person: "infinitive", Form: np.Infinitive, label: "infinitivo"},
+					{Person: "gerund", Form: np.Gerund, label: "gerundio"},
+					{Person: "participle", Form: np.Participle, label: "participio"},
+					{Person: "compound_infinitive", Form: np.CompoundInfinitive, label: "infinitivo compuesto"},
+					{Person: "compound_gerund", Form: np.CompoundGerund, label: "gerundio compuesto"},
+				}
+			},
+		}},
+	},
+	{
+		key:     "indicative",
+		label:   "Modo indicativo",
+		aliases: []string{"indicativo"},
+		tenses: []conjugationTense{
+			{"present", "Presente", func(c *rae.Conjugations) []conjugationForm {
+				return personForms(c.ConjugationIndicative.Present)
+			}},
+			{"present_perfect", "Pretérito perfecto compuesto", func(c *rae.Conjugations) []conjugationForm {
+				return personForms(c.ConjugationIndicative.PresentPerfect)
+			}},
+			{"imperfect", "Pretérito imperfecto", func(c *rae.Conjugations) []conjugationForm {
+				return personForms(c.ConjugationIndicative.Imperfect)
+			}},
+			{"past_perfect", "Pretérito pluscuamperfecto", func(c *rae.Conjugations) []conjugationForm {
+				return personForms(c.ConjugationIndicative.PastPerfect)
+			}},
+			{"preterite", "Pretérito perfecto simple", func(c *rae.Conjugations) []conjugationForm {
+				return personForms(c.ConjugationIndicative.Preterite)
+			}},
+			{"past_anterior", "Pretérito anterior", func(c *rae.Conjugations) []conjugationForm {
+				return personForms(c.ConjugationIndicative.PastAnterior)
+			}},
+			{"future", "Futuro simple", func(c *rae.Conjugations) []conjugationForm {
+				return personForms(c.ConjugationIndicative.Future)
+			}},
+			{"future_perfect", "Futuro compuesto", func(c *rae.Conjugations) []conjugationForm {
+				return personForms(c.ConjugationIndicative.FuturePerfect)
+			}},
+			{"conditional", "Condicional simple", func(c *rae.Conjugations) []conjugationForm {
+				return personForms(c.ConjugationIndicative.Conditional)
+			}},
+			{"conditional_perfect", "Condicional compuesto", func(c *rae.Conjugations) []conjugationForm {
+				return personForms(c.ConjugationIndicative.ConditionalPerfect)
+			}},
+		},
+	},
+	{
+		key:     "subjunctive",
+		label:   "Modo subjuntivo",
+		aliases: []string{"subjuntivo"},
+		tenses: []conjugationTense{
+			{"present", "Presente", func(c *rae.Conjugations) []conjugationForm {
+				return personForms(c.ConjugationSubjunctive.Present)
+			}},
+			{"present_perfect", "Pretérito perfecto compuesto", func(c *rae.Conjugations) []conjugationForm {
+				return personForms(c.ConjugationSubjunctive.PresentPerfect)
+			}},
+			{"imperfect", "Pretérito imperfecto", func(c *rae.Conjugations) []conjugationForm {
+				return personForms(c.ConjugationSubjunctive.Imperfect)
+			}},
+			{"past_perfect", "Pretérito pluscuamperfecto", func(c *rae.Conjugations) []conjugationForm {
+				return personForms(c.ConjugationSubjunctive.PastPerfect)
+			}},
+			{"future", "Futuro simple", func(c *rae.Conjugations) []conjugationForm {
+				return personForms(c.ConjugationSubjunctive.Future)
+			}},
+			{"future_perfect", "Futuro compuesto", func(c *rae.Conjugations) []conjugationForm {
+				return personForms(c.ConjugationSubjunctive.FuturePerfect)
+			}},
+		},
+	},
+	{
+		key:     "imperative",
+		label:   "Modo imperativo",
+		aliases: []string{"imperativo"},
+		tenses: []conjugationTense{{
+			forms: func(c *rae.Conjugations) []conjugationForm {
+				imp := c.ConjugationImperative
+				return []conjugationForm{
+					personTu.form(imp.SingularSecondPerson),
+					personUsted.form(imp.SingularFormalSecondPerson),
+					personVosotros.form(imp.PluralSecondPerson),
+					personUstedes.form(imp.PluralFormalSecondPerson),
+				}
+			},
+		}},
+	},
+}
+
+// conjugationFilter selects moods, tenses and persons by key. Nil sets
+// select everything
+type conjugationFilter struct {
+	moods, tenses, persons map[string]bool
+}
+
+// foldName lowercases s and strips its accents and separators, so that
+// "Pretérito imperfecto" and "preterito-imperfecto" compare equal
+func foldName(s string) string {
+	s = strings.NewReplacer(
+		"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u",
+		"_", " ", "-", " ",
+	).Replace(strings.ToLower(strings.TrimSpace(s)))
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// newConjugationFilter resolves the --mood, --tense and --person values, each
+// of them repeatable or comma separated, in English keys or Spanish names
+func newConjugationFilter(moods, tenses, persons []string) (conjugationFilter, error) {
+	var f conjugationFilter
+	var err error
+
+	if f.moods, err = resolveNames("modo", moods, func(name string) []string {
+		var keys []string
+		for _, m := range conjugationMoods {
+			if matchesName(name, append([]string{m.key, m.label}, m.aliases...)...) {
+				keys = append(keys, m.key)
+			}
+		}
+		return keys
+	}); err != nil {
+		return f, err
+	}
+
+	if f.tenses, err = resolveNames("tiempo", tenses, func(name string) []string {
+		var keys []string
+		for _, m := range conjugationMoods {
+			for _, t := range m.tenses {
+				if t.key != "" && matchesName(name, t.key, t.label) {
+					keys = append(keys, t.key)
+				}
+			}
+		}
+		return keys
+	}); err != nil {
+		return f, err
+	}
+
+	f.persons, err = resolveNames("persona", persons, func(name string) []string {
+		for _, p := range conjugationPersons {
+			if matchesName(name, append([]string{p.key}, strings.Split(p.label, ",")...)...) {
+				return []string{p.key}
+			}
+		}
+		return nil
+	})
+	return f, err
+}
+
+func matchesName(name string, candidates ...string) bool {
+	for _, c := range candidates {
+		if foldName(c) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func resolveNames(kind string, values []string, resolve func(name string) []string) (map[string]bool, error) {
+	var set map[string]bool
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			name := foldName(part)
+			if name == "" {
+				continue
+			}
+			keys := resolve(name)
+			if len(keys) == 0 {
+				return nil, fmt.Errorf(tr("%s desconocido: %q"), tr(kind), part)
+			}
+			if set == nil {
+				set = make(map[string]bool)
+			}
+			for _, key := range keys {
+				set[key] = true
+			}
+		}
+	}
+	return set, nil
+}
+
+// conjugationTables returns the tables of c selected by f, leaving out the
+// forms the API did not provide
+func conjugationTables(c *rae.Conjugations, f conjugationFilter) []conjugationTable {
+	var tables []conjugationTable
+	for _, mood := range conjugationMoods {
+		if f.moods != nil && !f.moods[mood.key] {
+			continue
+		}
+		for _, tense := range mood.tenses {
+			if f.tenses != nil && !f.tenses[tense.key] {
+				continue
+			}
+
+			table := conjugationTable{
+				Mood:       mood.key,
+				Tense:      tense.key,
+				moodLabel:  mood.label,
+				tenseLabel: tense.label,
+			}
+			for _, form := range tense.forms(c) {
+				if form.Form == "" || (f.persons != nil && !f.persons[form.Person]) {
+					continue
+				}
+				table.Forms = append(table.Forms, form)
+			}
+			if len(table.Forms) > 0 {
+				tables = append(tables, table)
+			}
+		}
+	}
+	return tables
+}
+
+// conjugationStyle decorates the already padded parts of rendered tables
+type conjugationStyle struct {
+	mood, tense, person func(string) string
+}
+
+var ansiConjugationStyle = conjugationStyle{
+	mood:   func(s string) string { return Bold + Title + s + Reset },
+	tense:  func(s string) string { return Bold + s + Reset },
+	person: func(s string) string { return Info + s + Reset },
+}
+
+func padRight(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// renderConjugationTables writes tables as aligned text, singular and plural
+// persons side by side, every line prefixed with indent
+func renderConjugationTables(w io.Writer, tables []conjugationTable, style conjugationStyle, indent string) {
+	// Columns are aligned across all tables
+	var labelWidth, formWidth int
+	for _, table := range tables {
+		for _, form := range table.Forms {
+			labelWidth = max(labelWidth, utf8.RuneCountInString(form.label))
+			if !form.plural {
+				formWidth = max(formWidth, utf8.RuneCountInString(form.Form))
+			}
+		}
+	}
+
+	mood := ""
+	for _, table := range tables {
+		if table.Mood != mood {
+			if mood != "" {
+				fmt.Fprintln(w)
+			}
+			mood = table.Mood
+			fmt.Fprintf(w, "%s%s\n", indent, style.mood(table.moodLabel))
+		}
+		if table.tenseLabel != "" {
+			fmt.Fprintf(w, "%s  %s\n", indent, style.tense(table.tenseLabel))
+		}
+
+		var singular, plural []conjugationForm
+		for _, form := range table.Forms {
+			if form.plural {
+				plural = append(plural, form)
+			} else {
+				singular = append(singular, form)
+			}
+		}
+
+		for i := 0; i < max(len(singular), len(plural)); i++ {
+			line := indent + "    "
+			if i < len(singular) {
+				line += style.person(padRight(singular[i].label, labelWidth)) + "  " + padRight(singular[i].Form, formWidth)
+			} else {
+				line += strings.Repeat(" ", labelWidth+2+formWidth)
+			}
+			if i < len(plural) {
+				line += "    " + style.person(padRight(plural[i].label, labelWidth)) + "  " + plural[i].Form
+			}
+			fmt.Fprintln(w, strings.TrimRight(line, " "))
+		}
+	}
+}
+
+// jsonConjugation is the document written by `conjugate --format json`
+type jsonConjugation struct {
+	SchemaVersion int                `json:"schema_version"`
+	Query         string             `json:"query"`
+	Status        lookupStatus       `json:"status"`
+	Offline       bool               `json:"offline,omitempty"`
+	Verb          string             `json:"verb,omitempty"`
+	Tables        []conjugationTable `json:"tables,omitempty"`
+	Suggestions   []string           `json:"suggestions,omitempty"`
+	Error         string             `json:"error,omitempty"`
+}
+
+func writeConjugationCSV(w io.Writer, verb string, tables []conjugationTable) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"verb", "mood", "tense", "person", "form"}); err != nil {
+		return err
+	}
+	for _, table := range tables {
+		for _, form := range table.Forms {
+			if err := cw.Write([]string{verb, table.Mood, table.Tense, form.Person, form.Form}); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// entryConjugations returns the conjugations of the first meaning of entry
+// that has them, or nil if entry is not a verb
+func entryConjugations(entry rae.WordEntry) *rae.Conjugations {
+	for _, meaning := range entry.Meanings {
+		if meaning.Conjugations != nil {
+			return meaning.Conjugations
+		}
+	}
+	return nil
+}
+
+// conjugationJSON looks verb up and selects its paradigm with filter
+func conjugationJSON(ctx context.Context, cli dictionary, verb string, filter conjugationFilter) jsonConjugation {
+	doc := jsonConjugation{SchemaVersion: jsonSchemaVersion, Query: verb}
+
+	entry, err := cli.Word(ctx, verb)
+	switch {
+	case err != nil && len(entry.Suggestions) > 0:
+		doc.Status = statusSuggested
+		doc.Suggestions = entry.Suggestions
+	case err != nil && entry.Word == "":
+		doc.Status = statusError
+		doc.Error = err.Error()
+	case err != nil:
+		doc.Status = statusNotFound
+	default:
+		conjugations := entryConjugations(entry)
+		if conjugations == nil {
+			doc.Status = statusNotFound
+			doc.Error = fmt.Sprintf("«%s» no es un verbo", entry.Word)
+			break
+		}
+		doc.Status = statusFound
+		doc.Verb = entry.Word
+		doc.Tables = conjugationTables(conjugations, filter)
+	}
+	return doc
+}
+
+// renderConjugation prints the paradigm of verb selected by filter and
+// returns the exit code of the lookup
+func renderConjugation(
+	ctx context.Context,
+	cli dictionary,
+	verb string,
+	filter conjugationFilter,
+	format outputFormat,
+) int {
+	doc := conjugationJSON(ctx, cli, verb, filter)
+	doc.Offline = isOffline(cli)
+
+	switch format {
+	case formatJSON:
+		if err := writeJSON(os.Stdout, doc); err != nil {
+			return exitError
+		}
+		return doc.Status.exitCode()
+	case formatCSV:
+		if doc.Status == statusFound {
+			if err := writeConjugationCSV(os.Stdout, doc.Verb, doc.Tables); err != nil {
+				fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+				return exitError
+			}
+			return exitFound
+		}
+	}
+
+	switch doc.Status {
+	case statusFound:
+		if isOffline(cli) {
+			fmt.Printf(tr("%s[offline] Sin conexión: resultado del diccionario local%s\n"), Accent, Reset)
+		}
+		fmt.Printf("%s%s%s\n\n", Bold, doc.Verb, Reset)
+		if len(doc.Tables) == 0 {
+			fmt.Println(tr("Ninguna forma coincide con los filtros"))
+			return exitFound
+		}
+		renderConjugationTables(os.Stdout, doc.Tables, ansiConjugationStyle, "")
+	case statusSuggested:
+		fmt.Fprintf(os.Stderr, tr("rae-tui: no se encontró «%s». ¿Quisiste decir: %s?\n"), verb, strings.Join(doc.Suggestions, ", "))
+	case statusNotFound:
+		if doc.Error != "" {
+			fmt.Fprintf(os.Stderr, "rae-tui: %s\n", doc.Error)
+		} else {
+			fmt.Fprintf(os.Stderr, tr("rae-tui: no se encontró «%s»\n"), verb)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "rae-tui: %s\n", doc.Error)
+	}
+	return doc.Status.exitCode()
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	rae "github.com/rae-api-com/go-rae"
+)
+
+func TestNewConjugationFilter(t *testing.T) {
+	tests := []struct {
+		name                   string
+		moods, tenses, persons []string
+		want                   conjugationFilter
+		err                    string
+	}{
+		{name: "no filter"},
+		{
+			name:  "english keys",
+			moods: []string{"indicative"}, tenses: []string{"present"}, persons: []string{"yo"},
+			want: conjugationFilter{
+				moods:   map[string]bool{"indicative": true},
+				tenses:  map[string]bool{"present": true},
+				persons: map[string]bool{"yo": true},
+			},
+		},
+		{
+			name:  "spanish names without accents",
+			moods: []string{"Subjuntivo"}, tenses: []string{"preterito-imperfecto"}, persons: []string{"tu"},
+			want: conjugationFilter{
+				moods:   map[string]bool{"subjunctive": true},
+				tenses:  map[string]bool{"imperfect": true},
+				persons: map[string]bool{"tu": true},
+			},
+		},
+		{
+			name:  "comma separated and repeated",
+			moods: []string{"indicativo,imperativo", "no personales"},
+			want: conjugationFilter{
+				moods: map[string]bool{"indicative": true, "imperative": true, "non_personal": true},
+			},
+		},
+		{
+			name:    "pronoun of a plural person",
+			persons: []string{"ellas"},
+			want:    conjugationFilter{persons: map[string]bool{"ellos": true}},
+		},
+		{name: "unknown mood", moods: []string{"condicional"}, err: "modo desconocido"},
+		{name: "unknown tense", tenses: []string{"pasado"}, err: "tiempo desconocido"},
+		{name: "unknown person", persons: []string{"vos"}, err: `"vos"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := newConjugationFilter(tt.moods, tt.tenses, tt.persons)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !maps.Equal(f.moods, tt.want.moods) {
+				t.Errorf("moods = %v, want %v", f.moods, tt.want.moods)
+			}
+			if !maps.Equal(f.tenses, tt.want.tenses) {
+				t.Errorf("tenses = %v, want %v", f.tenses, tt.want.tenses)
+			}
+			if !maps.Equal(f.persons, tt.want.persons) {
+				t.Errorf("persons = %v, want %v", f.persons, tt.want.persons)
+			}
+		})
+	}
+}
+
+func TestConjugationTables(t *testing.T) {
+	var c rae.Conjugations
+	c.ConjugationNonPersonal.Infinitive = "amar"
+	c.ConjugationIndicative.Present = rae.Conjugation{SingularFirstPerson: "amo", PluralThirdPerson: "aman"}
+	c.ConjugationSubjunctive.Present = rae.Conjugation{SingularFirstPerson: "ame"}
+	c.ConjugationImperative.SingularSecondPerson = "ama"
+
+	tests := []struct {
+		name                   string
+		moods, tenses, persons []string
+		// want lists the tables as mood/tense:forms
+		want []string
+	}{
+		{
+			name: "everything given",
+			want: []string{"non_personal/:amar", "indicative/present:amo,aman", "subjunctive/present:ame", "imperative/:ama"},
+		},
+		{name: "a mood", moods: []string{"subjuntivo"}, want: []string{"subjunctive/present:ame"}},
+		{
+			name:   "a tense of every mood",
+			tenses: []string{"presente"},
+			want:   []string{"indicative/present:amo,aman", "subjunctive/present:ame"},
+		},
+		{name: "a person", persons: []string{"ellos"}, want: []string{"indicative/present:aman"}},
+		{name: "nothing given", moods: []string{"indicative"}, tenses: []string{"future"}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := newConjugationFilter(tt.moods, tt.tenses, tt.persons)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, table := range conjugationTables(&c, f) {
+				var forms []string
+				for _, form := range table.Forms {
+					forms = append(forms, form.Form)
+				}
+				got = append(got, table.Mood+"/"+table.Tense+":"+strings.Join(forms, ","))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("tables = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,392 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"slices"
+	"strings"
+
+	rae "github.com/rae-api-com/go-rae"
+)
+
+// exportFormats are the formats entries can be exported to
+var exportFormats = []outputFormat{formatMarkdown, formatAnki, formatHTML}
+
+func validExportFormat(format outputFormat) error {
+	if !slices.Contains(exportFormats, format) {
+		return fmt.Errorf(tr("formato de exportación desconocido: %q (usa markdown, anki o html)"), format)
+	}
+	return nil
+}
+
+// exportExtension is the file extension of format, .txt for Anki as its
+// importer expects
+func exportExtension(format outputFormat) string {
+	switch format {
+	case formatAnki:
+		return ".txt"
+	case formatHTML:
+		return ".html"
+	default:
+		return ".md"
+	}
+}
+
+// exportFileName turns word into a file name, keeping its accents but none
+// of the characters file systems reject
+func exportFileName(word string, format outputFormat) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case strings.ContainsRune(`/\:*?"<>|`, r), r < ' ':
+			return -1
+		case r == ' ':
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(word))
+	if name == "" || name == "." || name == ".." {
+		name = "rae"
+	}
+	return name + exportExtension(format)
+}
+
+// exportEntry is an entry to export and the tags of its Anki note
+type exportEntry struct {
+	Entry rae.WordEntry
+	Tags  []string
+}
+
+// exportView is an entry laid out for the Markdown and HTML templates
+type exportView struct {
+	Word         string
+	Meanings     []exportMeaning
+	Conjugations []conjugationGrid
+}
+
+type exportMeaning struct {
+	// Title is the word with its homonym index, empty when there is only one
+	Title       string
+	Origin      string
+	Definitions []rae.Definition
+	Locutions   []rae.Locution
+}
+
+// conjugationGrid is a mood laid out as a table of persons by tenses
+type conjugationGrid struct {
+	Mood   string
+	Tenses []string
+	Rows   []conjugationRow
+}
+
+type conjugationRow struct {
+	Person string
+	Forms  []string
+}
+
+func newExportView(entry rae.WordEntry) exportView {
+	view := exportView{Word: entry.Word}
+	for _, meaning := range entry.Meanings {
+		m := exportMeaning{Definitions: meaning.Definitions, Locutions: meaning.Locutions}
+		if meaning.HomonymIndex > 0 {
+			m.Title = entry.Word + superscript(meaning.HomonymIndex)
+		}
+		if meaning.Origin != nil {
+			m.Origin = meaning.Origin.Raw
+		}
+		view.Meanings = append(view.Meanings, m)
+	}
+	if conjugations := entryConjugations(entry); conjugations != nil {
+		view.Conjugations = conjugationGrids(conjugationTables(conjugations, conjugationFilter{}))
+	}
+	return view
+}
+
+// conjugationGrids merges the tables of each mood into one grid, the rows in
+// the order their persons first appear
+func conjugationGrids(tables []conjugationTable) []conjugationGrid {
+	var grids []conjugationGrid
+	for _, table := range tables {
+		if len(grids) == 0 || grids[len(grids)-1].Mood != table.moodLabel {
+			grids = append(grids, conjugationGrid{Mood: table.moodLabel})
+		}
+		grid := &grids[len(grids)-1]
+
+		tense := table.tenseLabel
+		if tense == "" {
+			tense = "Forma"
+		}
+		grid.Tenses = append(grid.Tenses, tense)
+		column := len(grid.Tenses) - 1
+
+		for _, form := range table.Forms {
+			i := slices.IndexFunc(grid.Rows, func(row conjugationRow) bool { return row.Person == form.label })
+			if i < 0 {
+				grid.Rows = append(grid.Rows, conjugationRow{Person: form.label})
+				i = len(grid.Rows) - 1
+			}
+			for len(grid.Rows[i].Forms) <= column {
+				grid.Rows[i].Forms = append(grid.Rows[i].Forms, "")
+			}
+			grid.Rows[i].Forms[column] = form.Form
+		}
+	}
+
+	// Rows missing the last tenses are padded to the width of the grid
+	for g := range grids {
+		for r := range grids[g].Rows {
+			row := &grids[g].Rows[r]
+			for len(row.Forms) < len(grids[g].Tenses) {
+				row.Forms = append(row.Forms, "")
+			}
+		}
+	}
+	return grids
+}
+
+// plainRelatedWords lists synonyms or antonyms with their labels, e.g.
+// "vivienda, hogar (coloq.)"
+func plainRelatedWords(words []rae.RelatedWord) string {
+	parts := make([]string, len(words))
+	for i, w := range words {
+		parts[i] = w.Word
+		if w.Label != "" {
+			parts[i] += " (" + w.Label + ")"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// exportEntries writes entries in format: Markdown notes, an Anki import
+// file or a standalone HTML page
+func exportEntries(w io.Writer, entries []exportEntry, format outputFormat) error {
+	switch format {
+	case formatAnki:
+		return exportAnki(w, entries)
+	case formatHTML:
+		return exportHTML(w, entries)
+	default:
+		return exportMarkdown(w, entries)
+	}
+}
+
+// markdownCell escapes s for a Markdown table cell
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+func exportMarkdown(w io.Writer, entries []exportEntry) error {
+	var b strings.Builder
+	for i, item := range entries {
+		if i > 0 {
+			b.WriteString("\n---\n\n")
+		}
+		view := newExportView(item.Entry)
+		fmt.Fprintf(&b, "# %s\n", view.Word)
+
+		for _, meaning := range view.Meanings {
+			if meaning.Title != "" {
+				fmt.Fprintf(&b, "\n## %s\n", meaning.Title)
+			}
+			if meaning.Origin != "" {
+				fmt.Fprintf(&b, "\n*%s*\n", meaning.Origin)
+			}
+			if len(meaning.Definitions) > 0 {
+				b.WriteString("\n")
+			}
+			for _, def := range meaning.Definitions {
+				fmt.Fprintf(&b, "- %s\n", def.Raw)
+				for _, ex := range def.Examples {
+					fmt.Fprintf(&b, "  - *%s*\n", ex)
+				}
+				if len(def.SynonymsV2) > 0 {
+					fmt.Fprintf(&b, tr("  - **Sin.:** %s\n"), plainRelatedWords(def.SynonymsV2))
+				}
+				if len(def.AntonymsV2) > 0 {
+					fmt.Fprintf(&b, tr("  - **Ant.:** %s\n"), plainRelatedWords(def.AntonymsV2))
+				}
+			}
+			if len(meaning.Locutions) > 0 {
+				b.WriteString("\n### Locuciones\n\n")
+				for _, loc := range meaning.Locutions {
+					fmt.Fprintf(&b, "- **%s**\n", loc.Expression)
+					for _, sense := range loc.Senses {
+						fmt.Fprintf(&b, "  - %s\n", sense.Raw)
+					}
+				}
+			}
+		}
+
+		if len(view.Conjugations) > 0 {
+			b.WriteString("\n## Conjugación\n")
+		}
+		for _, grid := range view.Conjugations {
+			fmt.Fprintf(&b, "\n### %s\n\n", grid.Mood)
+			fmt.Fprintf(&b, "| | %s |\n", strings.Join(grid.Tenses, " | "))
+			fmt.Fprintf(&b, "|---%s\n", strings.Repeat("|---", len(grid.Tenses))+"|")
+			for _, row := range grid.Rows {
+				cells := make([]string, len(row.Forms))
+				for i, form := range row.Forms {
+					cells[i] = markdownCell(form)
+				}
+				fmt.Fprintf(&b, "| %s | %s |\n", row.Person, strings.Join(cells, " | "))
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var exportTemplates = template.Must(template.New("export").
+	Funcs(template.FuncMap{"related": plainRelatedWords}).
+	Parse(`
+{{- define "entry" -}}
+{{- range .Meanings -}}
+{{- with .Title}}<h2>{{.}}</h2>{{end -}}
+{{- with .Origin}}<p class="origin"><i>{{.}}</i></p>{{end -}}
+{{- range .Definitions -}}
+<div class="sense"><p>{{.Raw}}</p>
+{{- range .Examples}}<p class="example"><i>{{.}}</i></p>{{end -}}
+{{- with .SynonymsV2}}<p class="related"><b>Sin.:</b> {{related .}}</p>{{end -}}
+{{- with .AntonymsV2}}<p class="related"><b>Ant.:</b> {{related .}}</p>{{end -}}
+</div>
+{{- end -}}
+{{- with .Locutions}}<h3>Locuciones</h3>
+{{- range .}}<div class="locution"><p><b>{{.Expression}}</b></p>
+{{- range .Senses}}<p>{{.Raw}}</p>{{end -}}
+</div>{{end -}}
+{{- end -}}
+{{- end -}}
+{{- with .Conjugations}}<details><summary>Conjugación</summary>
+{{- range .}}<h4>{{.Mood}}</h4><table><tr><th></th>
+{{- range .Tenses}}<th>{{.}}</th>{{end}}</tr>
+{{- range .Rows}}<tr><th>{{.Person}}</th>{{range .Forms}}<td>{{.}}</td>{{end}}</tr>{{end -}}
+</table>{{end -}}
+</details>{{end -}}
+{{- end -}}
+
+{{- define "page" -}}
+<!DOCTYPE html>
+<html lang="es">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: Georgia, serif; max-width: 48rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.5; color: #222; }
+nav ul { columns: 3; list-style: none; padding: 0; }
+article { border-top: 1px solid #ccc; padding-top: 1rem; margin-top: 2rem; }
+h1 { color: #2e7d32; }
+.origin, .example { color: #666; }
+.example { margin-left: 1.5rem; }
+.related { margin-left: 1.5rem; font-size: 0.95em; }
+.locution { margin-left: 1rem; }
+table { border-collapse: collapse; margin-bottom: 1rem; font-size: 0.9em; }
+th, td { border: 1px solid #ddd; padding: 0.2rem 0.5rem; text-align: left; }
+summary { cursor: pointer; font-weight: bold; }
+</style>
+</head>
+<body>
+{{- if gt (len .Entries) 1}}
+<nav><ul>
+{{- range $i, $e := .Entries}}<li><a href="#e{{$i}}">{{$e.Word}}</a></li>{{end -}}
+</ul></nav>
+{{- end}}
+{{range $i, $e := .Entries -}}
+<article id="e{{$i}}"><h1>{{$e.Word}}</h1>
+{{template "entry" $e}}
+</article>
+{{end -}}
+</body>
+</html>
+{{end -}}
+`))
+
+func exportHTML(w io.Writer, entries []exportEntry) error {
+	page := struct {
+		Title   string
+		Entries []exportView
+	}{Title: "Diccionario RAE"}
+
+	for _, item := range entries {
+		page.Entries = append(page.Entries, newExportView(item.Entry))
+	}
+	if len(page.Entries) == 1 {
+		page.Title = page.Entries[0].Word + " — " + page.Title
+	}
+	return exportTemplates.ExecuteTemplate(w, "page", page)
+}
+
+// ankiFieldReplacer keeps every note on a single line of the import file
+var ankiFieldReplacer = strings.NewReplacer("\t", " ", "\r", "", "\n", "")
+
+// exportAnki writes a tab separated file for the Anki importer, one note per
+// entry: the word on the front and its HTML definition on the back, tagged
+// rae-tui plus the tags of the favorite
+func exportAnki(w io.Writer, entries []exportEntry) error {
+	var b strings.Builder
+	b.WriteString("#separator:tab\n#html:true\n#notetype:Basic\n#tags column:3\n")
+
+	for _, item := range entries {
+		var back strings.Builder
+		if err := exportTemplates.ExecuteTemplate(&back, "entry", newExportView(item.Entry)); err != nil {
+			return err
+		}
+
+		tags := []string{"rae-tui"}
+		for _, tag := range item.Tags {
+			tags = append(tags, strings.Join(strings.Fields(tag), "_"))
+		}
+
+		fmt.Fprintf(&b, "%s\t%s\t%s\n",
+			ankiFieldReplacer.Replace(template.HTMLEscapeString(item.Entry.Word)),
+			ankiFieldReplacer.Replace(back.String()),
+			strings.Join(tags, " "),
+		)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// exportToFile writes entries to path, replacing it only once complete
+func exportToFile(path string, entries []exportEntry, format outputFormat) error {
+	var b strings.Builder
+	if err := exportEntries(&b, entries, format); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, []byte(b.String()))
+}
+
+// exportCollector keeps the entries found by a batch, once per word, along
+// with the tags of their favorite
+type exportCollector struct {
+	favorites *favoriteStore
+	seen      map[string]bool
+	entries   []exportEntry
+}
+
+func newExportCollector(favorites *favoriteStore) *exportCollector {
+	return &exportCollector{favorites: favorites, seen: make(map[string]bool)}
+}
+
+func (c *exportCollector) Write(doc jsonLookup) error {
+	if doc.Entry == nil {
+		return nil
+	}
+	key := cacheKey(doc.Entry.Word)
+	if c.seen[key] {
+		return nil
+	}
+	c.seen[key] = true
+
+	item := exportEntry{Entry: *doc.Entry}
+	if c.favorites != nil {
+		if fav, ok := c.favorites.Get(doc.Query); ok {
+			item.Tags = fav.Tags
+		}
+	}
+	c.entries = append(c.entries, item)
+	return nil
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+func defaultFavoritesPath() string {
+	return filepath.Join(dataDir(), "favorites.json")
+}
+
+type favorite struct {
+	Word    string    `json:"word"`
+	Tags    []string  `json:"tags,omitempty"`
+	Note    string    `json:"note,omitempty"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// favoriteStore is the persisted set of bookmarked words, keyed by cacheKey
+type favoriteStore struct {
+	path string
+
+	mu    sync.Mutex
+	items map[string]favorite
+}
+
+// loadFavorites reads the favorites at path. A missing file is an empty set
+func loadFavorites(path string) (*favoriteStore, error) {
+	f := &favoriteStore{path: path, items: make(map[string]favorite)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return f, err
+	}
+
+	var items []favorite
+	if err := json.Unmarshal(data, &items); err != nil {
+		return f, err
+	}
+	for _, item := range items {
+		f.items[cacheKey(item.Word)] = item
+	}
+	return f, nil
+}
+
+func (f *favoriteStore) save() error {
+	data, err := json.MarshalIndent(f.sorted(nil), "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(f.path, data)
+}
+
+// sorted returns the favorites having any of tags, or all of them when no
+// tag is given, most recent first
+func (f *favoriteStore) sorted(tags []string) []favorite {
+	list := make([]favorite, 0, len(f.items))
+	for _, item := range f.items {
+		if len(tags) == 0 || slices.ContainsFunc(tags, func(tag string) bool { return hasTag(item, tag) }) {
+			list = append(list, item)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].AddedAt.Equal(list[j].AddedAt) {
+			return list[i].AddedAt.After(list[j].AddedAt)
+		}
+		return list[i].Word < list[j].Word
+	})
+	return list
+}
+
+func hasTag(item favorite, tag string) bool {
+	for _, t := range item.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *favoriteStore) List(tags ...string) []favorite {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.sorted(tags)
+}
+
+func (f *favoriteStore) Get(word string) (favorite, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	item, ok := f.items[cacheKey(word)]
+	return item, ok
+}
+
+func (f *favoriteStore) Has(word string) bool {
+	_, ok := f.Get(word)
+	return ok
+}
+
+// Put adds or updates a favorite, keeping the original AddedAt of updates
+func (f *favoriteStore) Put(item favorite) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := cacheKey(item.Word)
+	if prev, ok := f.items[key]; ok {
+		item.AddedAt = prev.AddedAt
+	}
+	if item.AddedAt.IsZero() {
+		item.AddedAt = time.Now()
+	}
+	item.Tags = normalizeTags(item.Tags)
+
+	f.items[key] = item
+	return f.save()
+}
+
+// Remove deletes word from the favorites and reports whether it was there
+func (f *favoriteStore) Remove(word string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := cacheKey(word)
+	if _, ok := f.items[key]; !ok {
+		return false, nil
+	}
+	delete(f.items, key)
+	return true, f.save()
+}
+
+// Toggle bookmarks word, or removes it if it already was, and reports whether
+// the word is a favorite afterwards
+func (f *favoriteStore) Toggle(word string) (bool, error) {
+	if f.Has(word) {
+		_, err := f.Remove(word)
+		return false, err
+	}
+	return true, f.Put(favorite{Word: word})
+}
+
+// normalizeTags trims, lowercases and deduplicates tags, splitting on commas
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]struct{}, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		for _, part := range strings.Split(tag, ",") {
+			part = strings.ToLower(strings.TrimSpace(part))
+			if part == "" {
+				continue
+			}
+			if _, ok := seen[part]; ok {
+				continue
+			}
+			seen[part] = struct{}{}
+			out = append(out, part)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func exportFavorites(w io.Writer, items []favorite, format outputFormat) error {
+	if format == formatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(items)
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"word", "tags", "note", "added_at"}); err != nil {
+		return err
+	}
+	for _, item := range items {
+		record := []string{
+			item.Word,
+			strings.Join(item.Tags, ","),
+			item.Note,
+			item.AddedAt.Format(time.RFC3339),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// runFavoritesCommand runs "favorites list|add|remove|export" with the
+// --tag and --note values given on the command line
+func runFavoritesCommand(store *favoriteStore, format outputFormat, params, tags []string, note string) int {
+	if len(params) == 0 {
+		params = []string{"list"}
+	}
+	positional := params[1:]
+
+	// Listing and exporting keep the favorites with any of the tags given
+	filter := normalizeTags(tags)
+
+	switch params[0] {
+	case "list":
+		items := store.List(filter...)
+		if format == formatJSON || format == formatCSV {
+			if err := exportFavorites(os.Stdout, items, format); err != nil {
+				fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+				return exitError
+			}
+			return exitFound
+		}
+		if len(items) == 0 {
+			fmt.Println(tr("No hay favoritos"))
+			return exitFound
+		}
+		for _, item := range items {
+			fmt.Printf("%s★ %s%s", Accent, item.Word, Reset)
+			if len(item.Tags) > 0 {
+				fmt.Printf(" %s[%s]%s", Info, strings.Join(item.Tags, ", "), Reset)
+			}
+			if item.Note != "" {
+				fmt.Printf(" - %s", item.Note)
+			}
+			fmt.Println()
+		}
+		return exitFound
+
+	case "add":
+		if len(positional) != 1 {
+			return usageFailure("favorites", "favorites add espera una palabra")
+		}
+		item := favorite{Word: positional[0], Tags: tags, Note: note}
+		if prev, ok := store.Get(item.Word); ok {
+			// Keep what was not given on the command line
+			if len(item.Tags) == 0 {
+				item.Tags = prev.Tags
+			}
+			if item.Note == "" {
+				item.Note = prev.Note
+			}
+		}
+		if err := store.Put(item); err != nil {
+			fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+			return exitError
+		}
+		fmt.Printf(tr("Añadida a favoritos: %s\n"), item.Word)
+		return exitFound
+
+	case "remove":
+		if len(positional) != 1 {
+			return usageFailure("favorites", "favorites remove espera una palabra")
+		}
+		removed, err := store.Remove(positional[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+			return exitError
+		}
+		if !removed {
+			fmt.Fprintf(os.Stderr, tr("rae-tui: %s no está en favoritos\n"), positional[0])
+			return exitNotFound
+		}
+		fmt.Printf(tr("Eliminada de favoritos: %s\n"), positional[0])
+		return exitFound
+
+	case "export":
+		w := io.Writer(os.Stdout)
+		if len(positional) > 0 {
+			f, err := os.Create(positional[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+				return exitError
+			}
+			defer f.Close()
+			w = f
+		}
+		if err := exportFavorites(w, store.List(filter...), format); err != nil {
+			fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+			return exitError
+		}
+		return exitFound
+
+	default:
+		return usageFailure("favorites", "subcomando de favoritos desconocido: %q", params[0])
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		want []string
+	}{
+		{name: "no tags", tags: nil, want: nil},
+		{name: "only blanks", tags: []string{"", " , "}, want: nil},
+		{name: "trimmed and lowercased", tags: []string{" Verbos ", "B1"}, want: []string{"verbos", "b1"}},
+		{name: "comma separated", tags: []string{"verbos,b1", "viajes"}, want: []string{"verbos", "b1", "viajes"}},
+		{name: "duplicates", tags: []string{"verbos", "VERBOS", "b1,verbos"}, want: []string{"verbos", "b1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeTags(tt.tags); !slices.Equal(got, tt.want) {
+				t.Errorf("normalizeTags(%q) = %q, want %q", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFavoriteStoreList(t *testing.T) {
+	store, err := loadFavorites(filepath.Join(t.TempDir(), "favorites.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	for i, item := range []favorite{
+		{Word: "casa", Tags: []string{"b1"}},
+		{Word: "correr", Tags: []string{"Verbos", "b1"}},
+		{Word: "perro"},
+	} {
+		item.AddedAt = now.Add(time.Duration(i) * time.Minute)
+		if err := store.Put(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		tags []string
+		want []string
+	}{
+		{tags: nil, want: []string{"perro", "correr", "casa"}},
+		{tags: []string{"b1"}, want: []string{"correr", "casa"}},
+		{tags: []string{"VERBOS"}, want: []string{"correr"}},
+		{tags: []string{"viajes"}, want: nil},
+		{tags: []string{"verbos", "viajes"}, want: []string{"correr"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.tags, ","), func(t *testing.T) {
+			var words []string
+			for _, item := range store.List(tt.tags...) {
+				words = append(words, item.Word)
+			}
+			if !slices.Equal(words, tt.want) {
+				t.Errorf("List(%q) = %q, want %q", tt.tags, words, tt.want)
+			}
+		})
+	}
+}
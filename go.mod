@@ -3,10 +3,12 @@ module github.com/rae-api-com/rae-tui
 go 1.24.0
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/gdamore/tcell/v2 v2.9.0
 	github.com/rae-api-com/go-rae v0.9.0
 	github.com/rivo/tview v0.42.0
 	github.com/sonirico/vago v0.9.0
+	golang.org/x/term v0.36.0
 )
 
 require (
@@ -25,6 +27,5 @@ require (
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasthttp v1.68.0 // indirect
 	golang.org/x/sys v0.37.0 // indirect
-	golang.org/x/term v0.36.0 // indirect
 	golang.org/x/text v0.30.0 // indirect
 )
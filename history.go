@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	rae "github.com/rae-api-com/go-rae"
+)
+
+// historyLimit is the number of lookups kept on disk
+const historyLimit = 500
+
+func defaultHistoryPath() string {
+	return filepath.Join(dataDir(), "history.json")
+}
+
+type historyEntry struct {
+	Word string    `json:"word"`
+	Time time.Time `json:"time"`
+}
+
+// lookupHistory is the persisted list of looked up words, oldest first
+type lookupHistory struct {
+	path string
+
+	mu      sync.Mutex
+	entries []historyEntry
+}
+
+// loadHistory reads the history at path. A missing file is an empty history
+func loadHistory(path string) (*lookupHistory, error) {
+	h := &lookupHistory{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return h, err
+	}
+
+	if err := json.Unmarshal(data, &h.entries); err != nil {
+		return h, err
+	}
+	return h, nil
+}
+
+// Add records a lookup of word and persists the history
+func (h *lookupHistory) Add(word string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries = append(h.entries, historyEntry{Word: word, Time: time.Now()})
+	if len(h.entries) > historyLimit {
+		h.entries = h.entries[len(h.entries)-historyLimit:]
+	}
+
+	data, err := json.Marshal(h.entries)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(h.path, data)
+}
+
+// Recent returns up to n distinct words, most recent first, with the time of
+// their last lookup. n <= 0 returns all of them
+func (h *lookupHistory) Recent(n int) []historyEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	seen := make(map[string]struct{}, len(h.entries))
+	recent := make([]historyEntry, 0)
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		entry := h.entries[i]
+		key := cacheKey(entry.Word)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		recent = append(recent, entry)
+		if n > 0 && len(recent) == n {
+			break
+		}
+	}
+	return recent
+}
+
+// runHistoryCommand prints up to limit distinct words of the history, most
+// recent first
+func runHistoryCommand(history *lookupHistory, format outputFormat, limit int) int {
+	recent := history.Recent(limit)
+
+	if format == formatJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(recent); err != nil {
+			fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+			return exitError
+		}
+		return exitFound
+	}
+
+	if len(recent) == 0 {
+		fmt.Println(tr("Todavía no has buscado ninguna palabra"))
+		return exitFound
+	}
+	for _, entry := range recent {
+		fmt.Printf("%s%s%s  %s\n", Info, entry.Time.Local().Format(time.DateTime), Reset, entry.Word)
+	}
+	return exitFound
+}
+
+// navigation is the browser-like back/forward stack of entries shown in the
+// TUI during the current session
+type navigation struct {
+	back    []rae.WordEntry
+	current *rae.WordEntry
+	forward []rae.WordEntry
+}
+
+// Visit makes entry the current one, dropping the forward stack
+func (n *navigation) Visit(entry rae.WordEntry) {
+	if n.current != nil {
+		n.back = append(n.back, *n.current)
+	}
+	n.current = &entry
+	n.forward = nil
+}
+
+func (n *navigation) CanGoBack() bool {
+	return len(n.back) > 0
+}
+
+func (n *navigation) CanGoForward() bool {
+	return len(n.forward) > 0
+}
+
+func (n *navigation) Back() (rae.WordEntry, bool) {
+	if !n.CanGoBack() {
+		return rae.WordEntry{}, false
+	}
+	if n.current != nil {
+		n.forward = append(n.forward, *n.current)
+	}
+	entry := n.back[len(n.back)-1]
+	n.back = n.back[:len(n.back)-1]
+	n.current = &entry
+	return entry, true
+}
+
+func (n *navigation) Forward() (rae.WordEntry, bool) {
+	if !n.CanGoForward() {
+		return rae.WordEntry{}, false
+	}
+	if n.current != nil {
+		n.back = append(n.back, *n.current)
+	}
+	entry := n.forward[len(n.forward)-1]
+	n.forward = n.forward[:len(n.forward)-1]
+	n.current = &entry
+	return entry, true
+}
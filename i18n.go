@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Languages of the interface. Definitions are always in Spanish
+const (
+	languageAuto    = "auto"
+	languageSpanish = "es"
+	languageEnglish = "en"
+)
+
+// language is the language of the interface, set once at startup
+var language = languageSpanish
+
+// catalogs translate the messages of the interface, written in Spanish in the
+// code, to the other languages
+var catalogs = map[string]map[string]string{
+	languageEnglish: englishMessages,
+}
+
+// setLanguage picks the language of the interface, reading the locale of the
+// environment for auto
+func setLanguage(lang string) {
+	if lang == languageAuto {
+		lang = localeLanguage()
+	}
+	language = lang
+}
+
+// localeLanguage returns English when the locale of the environment is an
+// English one, Spanish otherwise
+func localeLanguage() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale := os.Getenv(name); locale != "" {
+			if strings.HasPrefix(strings.ToLower(locale), languageEnglish) {
+				return languageEnglish
+			}
+			return languageSpanish
+		}
+	}
+	return languageSpanish
+}
+
+// tr returns msg in the language of the interface, or as is when it has no
+// translation
+func tr(msg string) string {
+	if translated, ok := catalogs[language][msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// messageError is an error whose message is translated when it is shown, so
+// that it can be declared before the language is set
+type messageError string
+
+func (e messageError) Error() string {
+	return tr(string(e))
+}
+
+// trf formats the translation of format with args
+func trf(format string, args ...any) string {
+	return fmt.Sprintf(tr(format), args...)
+}
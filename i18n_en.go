@@ -0,0 +1,299 @@
+package main
+
+// englishMessages is the English catalog, keeping the color tags, verbs and
+// layout of the Spanish messages
+var englishMessages = map[string]string{
+	// Lookups without the TUI
+	"«%s» es una forma de «%s»: %s":                                             "“%s” is a form of “%s”: %s",
+	"  %s0%s. Cancelar\n":                                                       "  %s0%s. Cancel\n",
+	"\n%sSelecciona una palabra (1-%d) o 0 para cancelar: %s":                   "\n%sSelect a word (1-%d) or 0 to cancel: %s",
+	"%sEntrada inválida. Por favor ingresa un número.%s\n":                      "%sInvalid input. Please enter a number.%s\n",
+	"%sCancelado.%s\n":                                                          "%sCancelled.%s\n",
+	"%sOpción inválida. Por favor selecciona un número entre 1 y %d.%s\n":       "%sInvalid option. Please select a number between 1 and %d.%s\n",
+	"\n%sBúsqueda difusa - Resultados encontrados:%s\n":                         "\n%sFuzzy search - Results found:%s\n",
+	"%sNo se encontraron resultados de búsqueda difusa para: %s%s\n":            "%sNo fuzzy search results for: %s%s\n",
+	"%s[offline] Sin conexión: resultados del diccionario local%s\n":            "%s[offline] No connection: results from the local dictionary%s\n",
+	"¿Quisiste decir:\n":                                                        "Did you mean:\n",
+	"\n%sBuscando: %s%s\n":                                                      "\n%sLooking up: %s%s\n",
+	"%sNo se encontró la palabra y no hay sugerencias disponibles para: %s%s\n": "%sWord not found and no suggestions available for: %s%s\n",
+	"%sBuscando resultados difusos...%s\n":                                      "%sLooking for fuzzy results...%s\n",
+	"%s[offline] Sin conexión: resultado del diccionario local%s\n":             "%s[offline] No connection: result from the local dictionary%s\n",
+	"\n%sPalabra: %s%s\n\n":                                                     "\n%sWord: %s%s\n\n",
+	"%sSignificado %d:%s\n":                                                     "%sMeaning %d:%s\n",
+	"\n  %sOrigen:%s %s\n":                                                      "\n  %sOrigin:%s %s\n",
+	"\n  %sConjugaciones%s\n":                                                   "\n  %sConjugations%s\n",
+	"%sPalabra del día · %s%s\n":                                                "%sWord of the day · %s%s\n",
+	"Ninguna forma coincide con los filtros":                                    "No form matches the filters",
+	"rae-tui: no se encontró «%s». ¿Quisiste decir: %s?\n":                      "rae-tui: “%s” was not found. Did you mean: %s?\n",
+	"rae-tui: no se encontró «%s»\n":                                            "rae-tui: “%s” was not found\n",
+
+	// Header, search modal and lists
+	"Diccionario RAE":          "RAE Dictionary",
+	"Buscar":                   "Search",
+	"Limpiar":                  "Clear",
+	"Buscar: ":                 "Search: ",
+	"Comparar con: ":           "Compare with: ",
+	"← Atrás":                  "← Back",
+	"Favorito":                 "Favorite",
+	"Guardar":                  "Save",
+	"Cancelar":                 "Cancel",
+	"Etiquetas: ":              "Tags: ",
+	"Nota: ":                   "Note: ",
+	"0. Cancelar":              "0. Cancel",
+	"[accent]Ir a:":            "[accent]Go to:",
+	"No se encontró «%s»":      "“%s” was not found",
+	"[accent]¿Quisiste decir?": "[accent]Did you mean?",
+	"[accent]Búsqueda difusa - Resultados encontrados:": "[accent]Fuzzy search - Results found:",
+	"No se encontraron resultados de búsqueda difusa":   "No fuzzy search results",
+	"[accent]Favoritos": "[accent]Favorites",
+	"[muted]Pulsa %s sobre una palabra para añadirla a favoritos": "[muted]Press %s on a word to add it to the favorites",
+	"[accent]Historial de búsquedas":                              "[accent]Search history",
+	"[muted]Todavía no has buscado ninguna palabra":               "[muted]You have not looked up any word yet",
+
+	// Footers
+	"[accent]%c[:] Buscando «%s»…  [accent]ESC[:] Cancelar":                                                 "[accent]%c[:] Looking up “%s”…  [accent]ESC[:] Cancel",
+	"[accent]↑/%s[:] Subir  ↓/%s[:] Bajar  ESC/%s[:] Cerrar  %s[:] Salir":                                   "[accent]↑/%s[:] Up  ↓/%s[:] Down  ESC/%s[:] Close  %s[:] Quit",
+	"[accent]↑/%s[:] Subir  ↓/%s[:] Bajar  Enter/1-9[:] Seleccionar  ESC[:] Volver  %s[:] Salir":            "[accent]↑/%s[:] Up  ↓/%s[:] Down  Enter/1-9[:] Select  ESC[:] Back  %s[:] Quit",
+	"[accent]↑/%s[:] Subir  ↓/%s[:] Bajar  Tab/Shift+Tab[:] Cambiar de modo  ESC/%s[:] Volver  %s[:] Salir": "[accent]↑/%s[:] Up  ↓/%s[:] Down  Tab/Shift+Tab[:] Switch mood  ESC/%s[:] Back  %s[:] Quit",
+	"[accent]ESC/%s[:] Volver  %s[:] Salir":                                                                 "[accent]ESC/%s[:] Back  %s[:] Quit",
+	"[accent]0-2[:] No la sabía  3[:] Difícil  4[:] Bien  5[:] Fácil  ESC[:] Terminar":                      "[accent]0-2[:] Didn't know it  3[:] Hard  4[:] Good  5[:] Easy  ESC[:] Finish",
+	"[accent]Espacio/Enter[:] Mostrar respuesta  ESC/%s[:] Terminar  %s[:] Salir":                           "[accent]Space/Enter[:] Show answer  ESC/%s[:] Finish  %s[:] Quit",
+	"[accent]↑/%s[:] Subir  ↓/%s[:] Bajar  Tab[:] Cambiar de panel  ESC/%s[:] Volver  %s[:] Salir":          "[accent]↑/%s[:] Up  ↓/%s[:] Down  Tab[:] Switch panel  ESC/%s[:] Back  %s[:] Quit",
+	"[accent]↑/%s[:] Subir  ↓/%s[:] Bajar  Enter[:] Seleccionar  ESC[:] Volver  %s[:] Salir":                "[accent]↑/%s[:] Up  ↓/%s[:] Down  Enter[:] Select  ESC[:] Back  %s[:] Quit",
+	"[accent]Tab[:] Siguiente campo  Enter[:] Confirmar  ESC[:] Cancelar":                                   "[accent]Tab[:] Next field  Enter[:] Confirm  ESC[:] Cancel",
+	"[accent]Enter[:] Comparar  ESC[:] Cancelar":                                                            "[accent]Enter[:] Compare  ESC[:] Cancel",
+	"[accent]Enter[:] Buscar  ESC[:] Cancelar":                                                              "[accent]Enter[:] Search  ESC[:] Cancel",
+	"%s  [accent]%s[:] Siguiente  %s[:] Anterior  %s[:] Buscar otra vez  ESC[:] Terminar  %s[:] Salir":      "%s  [accent]%s[:] Next  %s[:] Previous  %s[:] Find again  ESC[:] Finish  %s[:] Quit",
+	"[accent]↑/%s[:] Subir  ↓/%s[:] Bajar  Enter[:] Ir a palabra  Espacio[:] Plegar  %s[:] Plegar todo  %s[:] Desplegar todo  ←/%s[:] Atrás  →/%s[:] Adelante  " +
+		"%s[:] Historial  %s[:] Favorito  %s[:] Etiquetas  %s[:] Favoritos  %s[:] Conjugar  %s[:] Palabra del día  %s[:] Estudiar  %s[:] Exportar  %s[:] Copiar  %s[:] Comparar  %s[:] Buscar en la entrada  %s[:] Nueva búsqueda  %s[:] Ayuda  %s[:] Salir": "[accent]↑/%s[:] Up  ↓/%s[:] Down  Enter[:] Go to word  Space[:] Fold  %s[:] Fold all  %s[:] Unfold all  ←/%s[:] Back  →/%s[:] Forward  " +
+		"%s[:] History  %s[:] Favorite  %s[:] Tags  %s[:] Favorites  %s[:] Conjugate  %s[:] Word of the day  %s[:] Study  %s[:] Export  %s[:] Copy  %s[:] Compare  %s[:] Find in entry  %s[:] New search  %s[:] Help  %s[:] Quit",
+	"[error]«%s»: sin coincidencias[-]":       "[error]“%s”: no matches[-]",
+	"«%s»: línea %d de %d (%d coincidencias)": "“%s”: line %d of %d (%d matches)",
+
+	// Results, outline and conjugation
+	" Esquema ":                   " Outline ",
+	"[accent][::b]Origen:[-] %s":  "[accent][::b]Origin:[-] %s",
+	"Acepciones (%d)":             "Senses (%d)",
+	"[info]Sin.:[-] %s":           "[info]Syn.:[-] %s",
+	"[error]Ant.:[-] %s":          "[error]Ant.:[-] %s",
+	"[accent][::b]Locuciones[-]":  "[accent][::b]Locutions[-]",
+	"Locuciones (%d)":             "Locutions (%d)",
+	"Conjugación":                 "Conjugation",
+	"[accent][::b]Conjugación[-]": "[accent][::b]Conjugation[-]",
+	"[muted]Enter, %s o doble clic: ver todos los modos y tiempos": "[muted]Enter, %s or double click: see every mood and tense",
+	" Conjugación de «%s» ":                                        " Conjugation of “%s” ",
+	" En común ":                                                   " In common ",
+
+	// Comparisons
+	"Categorías: ":     "Categories: ",
+	" (solo aquí: %s)": " (only here: %s)",
+	"Sin. en común: ":  "Shared syn.: ",
+	"Sin.: ":           "Syn.: ",
+	"En común":         "In common",
+	"«%s» y «%s» se citan como sinónimos":     "“%s” and “%s” list each other as synonyms",
+	"«%s» figura entre los sinónimos de «%s»": "“%s” is among the synonyms of “%s”",
+	"Sinónimos: ":                     "Synonyms: ",
+	"Sinónimos: ninguno":              "Synonyms: none",
+	"solo %s: %s":                     "only %s: %s",
+	"Categorías distintas: ":          "Different categories: ",
+	"Definiciones parecidas: ninguna": "Similar definitions: none",
+	"Definiciones parecidas:":         "Similar definitions:",
+	"  y %d más":                      "  and %d more",
+	": ¿%s?":                          ": %s?",
+	"no se pudo buscar «%s»: %s":      "could not look up “%s”: %s",
+	"no se encontró «%s»; ¿quisiste decir: %s?": "“%s” was not found; did you mean: %s?",
+	"no se encontró «%s»":                       "“%s” was not found",
+
+	// Copies and exports
+	"la acepción":                "the sense",
+	"la entrada":                 "the entry",
+	"la entrada en Markdown":     "the entry as Markdown",
+	"[error]No se pudo copiar: ": "[error]Could not copy: ",
+	"[accent]Se copió %s solo en el terminal: %s": "[accent]Copied %s to the terminal only: %s",
+	"[success]Se copió %s al portapapeles (%s)":   "[success]Copied %s to the clipboard (%s)",
+	"[success]Se copió %s al portapapeles":        "[success]Copied %s to the clipboard",
+	"[error]No se pudo exportar: ":                "[error]Could not export: ",
+	"[success]Exportado a ":                       "[success]Exported to ",
+
+	// Study
+	" Estudio ":         " Study ",
+	" Estudio — %d/%d ": " Study — %d/%d ",
+	"[muted]Todavía no hay tarjetas: pulsa %s sobre una palabra para añadirla a favoritos": "[muted]No cards yet: press %s on a word to add it to the favorites",
+	"[muted]Todavía no hay tarjetas: busca algunas palabras primero":                       "[muted]No cards yet: look up some words first",
+	"[success]No hay tarjetas pendientes[-]":                                               "[success]No cards due[-]",
+	"\n\n[muted]Próximo repaso: ":                                                          "\n\n[muted]Next review: ",
+	"\n[success::b]¡Sesión terminada![-::-]\n\nHas repasado %d tarjetas.":                  "\n[success::b]Session finished![-::-]\n\nYou reviewed %d cards.",
+	"[info]nueva[-]":                             "[info]new[-]",
+	"[muted]¿Recuerdas qué significa?":           "[muted]Do you remember what it means?",
+	"[muted]Cargando…":                           "[muted]Loading…",
+	"[error]No se pudo cargar la definición: %s": "[error]Could not load the definition: %s",
+
+	// Help overlay
+	" Ayuda — %s ":                       " Help — %s ",
+	"Resultados":                         "Results",
+	"Lista":                              "List",
+	"Estudio":                            "Study",
+	"Comparación":                        "Comparison",
+	"Espacio":                            "Space",
+	"Clic":                               "Click",
+	"Doble clic":                         "Double click",
+	"Espacio/Enter":                      "Space/Enter",
+	"Subir":                              "Up",
+	"Bajar":                              "Down",
+	"Subir una página, o media":          "Page up, or half a page",
+	"Bajar una página, o media":          "Page down, or half a page",
+	"Ir al principio":                    "Go to the top",
+	"Ir al final":                        "Go to the bottom",
+	"Siguiente acepción":                 "Next sense",
+	"Acepción anterior":                  "Previous sense",
+	"Siguiente homónimo":                 "Next homonym",
+	"Homónimo anterior":                  "Previous homonym",
+	"Ir a las locuciones":                "Go to the locutions",
+	"Ir a la conjugación":                "Go to the conjugation",
+	"Ir a la palabra enlazada, o plegar": "Go to the linked word, or fold",
+	"Plegar o desplegar":                 "Fold or unfold",
+	"Ir a la palabra, o plegar en ▾ y ▸": "Go to the word, or fold on ▾ and ▸",
+	"Como Enter":                         "Like Enter",
+	"Plegar todo":                        "Fold all",
+	"Desplegar todo":                     "Unfold all",
+	"Atrás":                              "Back",
+	"Adelante":                           "Forward",
+	"Historial":                          "History",
+	"Etiquetas y nota del favorito":      "Tags and note of the favorite",
+	"Favoritos":                          "Favorites",
+	"Conjugar":                           "Conjugate",
+	"Palabra del día":                    "Word of the day",
+	"Estudiar":                           "Study",
+	"Exportar":                           "Export",
+	"Copiar la acepción":                 "Copy the sense",
+	"Copiar la entrada":                  "Copy the entry",
+	"Copiar la entrada en Markdown":      "Copy the entry as Markdown",
+	"Copiar la palabra":                  "Copy the word",
+	"Comparar":                           "Compare",
+	"Buscar en la entrada":               "Find in the entry",
+	"Siguiente coincidencia":             "Next match",
+	"Coincidencia anterior":              "Previous match",
+	"Nueva búsqueda":                     "New search",
+	"Volver":                             "Back",
+	"Ayuda":                              "Help",
+	"Salir":                              "Quit",
+	"Seleccionar":                        "Select",
+	"Seleccionar sugerencia":             "Select a suggestion",
+	"Cambiar de modo":                    "Switch mood",
+	"Cambiar de panel":                   "Switch panel",
+	"Mostrar respuesta":                  "Show the answer",
+	"No la sabía":                        "Didn't know it",
+	"Difícil":                            "Hard",
+	"Bien":                               "Good",
+	"Fácil":                              "Easy",
+	"Terminar":                           "Finish",
+
+	// Command output
+	"Todavía no has buscado ninguna palabra":           "You have not looked up any word yet",
+	"No hay favoritos":                                 "No favorites",
+	"Añadida a favoritos: %s\n":                        "Added to the favorites: %s\n",
+	"rae-tui: %s no está en favoritos\n":               "rae-tui: %s is not among the favorites\n",
+	"Eliminada de favoritos: %s\n":                     "Removed from the favorites: %s\n",
+	"Directorio:    %s\n":                              "Directory:     %s\n",
+	"Palabras:      %d\n":                              "Words:         %d\n",
+	"Búsquedas:     %d\n":                              "Searches:      %d\n",
+	"Tamaño:        %s / %s\n":                         "Size:          %s / %s\n",
+	"TTL palabra:   %s\n":                              "Word TTL:      %s\n",
+	"TTL búsqueda:  %s\n":                              "Search TTL:    %s\n",
+	"Más antigua:   %s\n":                              "Oldest:        %s\n",
+	"Más reciente:  %s\n":                              "Newest:        %s\n",
+	"Eliminadas %d entradas de la caché\n":             "Removed %d entries from the cache\n",
+	"Eliminadas %d entradas caducadas o sobrantes\n":   "Removed %d expired or excess entries\n",
+	"Exportadas %d palabras\n":                         "Exported %d words\n",
+	"Fichero:  %s\n":                                   "File:   %s\n",
+	"Palabras: %d\n":                                   "Words:  %d\n",
+	"Importadas %d palabras desde %s\n":                "Imported %d words from %s\n",
+	"la caché":                                         "the cache",
+	"la entrada estándar":                              "the standard input",
+	"Encontradas: %d  No encontradas: %d  Errores: %d": "Found: %d  Not found: %d  Errors: %d",
+	"  Ya procesadas: %d":                              "  Already done: %d",
+	"\nNo encontradas:":                                "\nNot found:",
+	"  %-20s ¿quisiste decir: %s?\n":                   "  %-20s did you mean: %s?\n",
+	"\nErrores:":                                       "\nErrors:",
+	"\nInterrumpido con %d palabras pendientes: repite el comando con --resume para continuar\n": "\nInterrupted with %d words left: run the command again with --resume to continue\n",
+	"\nInterrumpido con %d palabras pendientes\n":                                                "\nInterrupted with %d words left\n",
+	"*Forma de **%s**: %s*\n\n":                                                                  "*Form of **%s**: %s*\n\n",
+	"*No encontrada.* Quizá: %s\n":                                                               "*Not found.* Maybe: %s\n",
+	"*No encontrada.* Parecidas: %s\n":                                                           "*Not found.* Similar: %s\n",
+	"*No encontrada.*\n":                                                                         "*Not found.*\n",
+	"Tarjetas:          %d\n":                                                                    "Cards:             %d\n",
+	"Nuevas:            %d\n":                                                                    "New:               %d\n",
+	"Para hoy:          %d\n":                                                                    "Due today:         %d\n",
+	"En aprendizaje:    %d\n":                                                                    "Learning:          %d\n",
+	"Maduras:           %d\n":                                                                    "Mature:            %d\n",
+	"Repasos:           %d (%d fallos)\n":                                                        "Reviews:           %d (%d lapses)\n",
+	"Facilidad media:   %.2f\n":                                                                  "Average ease:      %.2f\n",
+	"Próximo repaso:    %s\n":                                                                    "Next review:       %s\n",
+	"No existe %s, se usan los valores por defecto\n":                                            "%s does not exist, the defaults are used\n",
+	"%s es válido\n":                                                                             "%s is valid\n",
+	"\nOrigen: %s\n":                                                                             "\nOrigin: %s\n",
+	"    Sin.: %s\n":                                                                             "    Syn.: %s\n",
+	"    Ant.: %s\n":                                                                             "    Ant.: %s\n",
+	"\nLocuciones\n":                                                                             "\nLocutions\n",
+	"\nConjugación\n":                                                                            "\nConjugation\n",
+	"  - **Sin.:** %s\n":                                                                         "  - **Syn.:** %s\n",
+	"  - **Ant.:** %s\n":                                                                         "  - **Ant.:** %s\n",
+
+	// Errors
+	"rae-tui: configuración inválida:\n%v\n":                             "rae-tui: invalid configuration:\n%v\n",
+	"argumentos no válidos\nUso: rae-tui %s":                             "invalid arguments\nUsage: rae-tui %s",
+	"%s no admite el formato %s":                                         "%s does not support the %s format",
+	"comando desconocido: %q":                                            "unknown command: %q",
+	"shell no soportada: %q":                                             "unsupported shell: %q",
+	"subcomando de caché desconocido: %q":                                "unknown cache subcommand: %q",
+	"subcomando de configuración desconocido: %q":                        "unknown config subcommand: %q",
+	"subcomando de favoritos desconocido: %q":                            "unknown favorites subcommand: %q",
+	"subcomando offline desconocido: %q":                                 "unknown offline subcommand: %q",
+	"favorites add espera una palabra":                                   "favorites add expects a word",
+	"favorites remove espera una palabra":                                "favorites remove expects a word",
+	"--jobs debe ser al menos 1":                                         "--jobs must be at least 1",
+	"--resume necesita --output":                                         "--resume needs --output",
+	"fecha inválida %q, usa AAAA-MM-DD":                                  "invalid date %q, use YYYY-MM-DD",
+	"--format %s solo se admite con --stats":                             "--format %s is only supported with --stats",
+	"--set espera CLAVE=VALOR, no %q":                                    "--set expects KEY=VALUE, not %q",
+	"ruta desconocida: ":                                                 "unknown path: ",
+	"falta la palabra":                                                   "missing word",
+	"falta el parámetro q":                                               "missing q parameter",
+	"limit inválido: ":                                                   "invalid limit: ",
+	"sin conexión y la palabra no está en el diccionario local":          "no connection and the word is not in the local dictionary",
+	"no hay palabras entre las que elegir":                               "there are no words to choose from",
+	"origen de palabras desconocido: %q (usa %s)":                        "unknown word source: %q (use %s)",
+	"nota inválida %d, debe estar entre 0 y %d":                          "invalid grade %d, it must be between 0 and %d",
+	"formato de exportación desconocido: %q (usa markdown, anki o html)": "unknown export format: %q (use markdown, anki or html)",
+	"formato de salida desconocido: %q (usa text, json, jsonl, csv, markdown, anki o html)": "unknown output format: %q (use text, json, jsonl, csv, markdown, anki or html)",
+	"%s desconocido: %q": "unknown %s: %q",
+	"modo":               "mood",
+	"tiempo":             "tense",
+	"persona":            "person",
+	"tema desconocido %q, usa %s o uno de [themes]":                          "unknown theme %q, use %s or one of [themes]",
+	"el tema %q se basa en sí mismo":                                         "theme %q is based on itself",
+	"%s: claves desconocidas: %s":                                            "%s: unknown keys: %s",
+	"variable de entorno desconocida: %s":                                    "unknown environment variable: %s",
+	"acción desconocida: %s":                                                 "unknown action: %s",
+	"clave desconocida: %s":                                                  "unknown key: %s",
+	"%s: duración inválida %q":                                               "%s: invalid duration %q",
+	"%s: booleano inválido %q":                                               "%s: invalid boolean %q",
+	"%s: número inválido %q":                                                 "%s: invalid number %q",
+	"api.timeout debe ser positivo":                                          "api.timeout must be positive",
+	"cache.word_ttl y cache.search_ttl no pueden ser negativos":              "cache.word_ttl and cache.search_ttl cannot be negative",
+	"cache.max_size_mb no puede ser negativo":                                "cache.max_size_mb cannot be negative",
+	"ui.preview_length y ui.tui_preview_length deben ser positivos":          "ui.preview_length and ui.tui_preview_length must be positive",
+	"ui.history_size debe ser positivo":                                      "ui.history_size must be positive",
+	"ui.autocomplete_delay no puede ser negativo":                            "ui.autocomplete_delay cannot be negative",
+	"ui.outline_width no puede ser negativo":                                 "ui.outline_width cannot be negative",
+	"server.listen: dirección inválida %q, usa HOST:PUERTO":                  "server.listen: invalid address %q, use HOST:PORT",
+	"study.new_cards no puede ser negativo":                                  "study.new_cards cannot be negative",
+	"themes.%s: no se puede redefinir un tema incorporado":                   "themes.%s: a built-in theme cannot be redefined",
+	"ui.language: debe ser auto, es o en, no %q":                             "ui.language: must be auto, es or en, not %q",
+	"ui.keymap: debe ser vim o emacs, no %q":                                 "ui.keymap: must be vim or emacs, not %q",
+	"keys.%s: acción desconocida":                                            "keys.%s: unknown action",
+	"keys.%s: tecla desconocida %q, usa un carácter o un nombre como Ctrl-N": "keys.%s: unknown key %q, use a character or a name such as Ctrl-N",
+	"keys.%s: la tecla %s está reservada":                                    "keys.%s: the key %s is reserved",
+	"keys.%s: la tecla %q ya está asignada a %s":                             "keys.%s: the key %q is already bound to %s",
+	"%s.%s: color desconocido %q":                                            "%s.%s: unknown color %q",
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var (
+	// messageNoise matches the verbs, escapes and color tags of a message
+	messageNoise = regexp.MustCompile(`%[-+# 0-9.*]*[a-zA-Z%]|\[[a-z:-]*\]`)
+	messageWord  = regexp.MustCompile(`\pL{2,}`)
+	// spanishText matches the accents and the most common short words of
+	// Spanish, which English messages lack
+	spanishText = regexp.MustCompile(`(?i)[áéíóúñ¿¡]|\b(de|del|el|la|las|los|no|y|o|en|con|sin|para|por|se|es|un|una|hay|debe|usa|desde)\b`)
+)
+
+// messageLiterals calls visit with every string literal passed to a function
+// of the non-test files, with the package and name of the function and the
+// position of the argument
+func messageLiterals(t *testing.T, visit func(pkg, fn string, arg int, msg string, pos token.Position)) {
+	t.Helper()
+
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			var pkg, fn string
+			switch fun := call.Fun.(type) {
+			case *ast.Ident:
+				fn = fun.Name
+			case *ast.SelectorExpr:
+				if x, ok := fun.X.(*ast.Ident); ok {
+					pkg = x.Name
+				}
+				fn = fun.Sel.Name
+			}
+			for i, arg := range call.Args {
+				lit, ok := arg.(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					continue
+				}
+				msg, err := strconv.Unquote(lit.Value)
+				if err != nil {
+					t.Fatal(err)
+				}
+				visit(pkg, fn, i, msg, fset.Position(lit.Pos()))
+			}
+			return true
+		})
+	}
+}
+
+func TestEnglishCatalog(t *testing.T) {
+	messageLiterals(t, func(pkg, fn string, arg int, msg string, pos token.Position) {
+		translated := pkg == "" && (fn == "tr" || fn == "trf" || fn == "messageError") && arg == 0 ||
+			pkg == "" && fn == "usageFailure" && arg == 1
+		if !translated || !messageWord.MatchString(messageNoise.ReplaceAllString(msg, " ")) {
+			return
+		}
+		if _, ok := englishMessages[msg]; !ok {
+			t.Errorf("%s: %q has no English translation", pos, msg)
+		}
+	})
+}
+
+func TestUntranslatedMessages(t *testing.T) {
+	messageLiterals(t, func(pkg, fn string, _ int, msg string, pos token.Position) {
+		printed := strings.HasPrefix(fn, "Print") || strings.HasPrefix(fn, "Fprint") || fn == "Errorf"
+		if pkg != "fmt" || !printed {
+			return
+		}
+		if spanishText.MatchString(messageNoise.ReplaceAllString(msg, " ")) {
+			t.Errorf("%s: %q is printed without tr", pos, msg)
+		}
+	})
+}
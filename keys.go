@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// keyBinding is a key as the terminal sends it: a rune, possibly with Alt, or
+// a special key such as Ctrl-N or F1
+type keyBinding struct {
+	key tcell.Key
+	ch  rune
+	alt bool
+}
+
+// altPrefix introduces runes pressed with Alt, e.g. "Alt-v"
+const altPrefix = "Alt-"
+
+// emacsKeys move with Ctrl-N, Ctrl-P, Ctrl-B and Ctrl-F, page with Ctrl-V
+// and Alt-v, find with Ctrl-S and go to the top or the bottom with < and >,
+// keeping the other bindings of the vim preset
+var emacsKeys = overrideKeys(defaultKeys, map[string]string{
+	"down":           "Ctrl-N",
+	"up":             "Ctrl-P",
+	"back":           "Ctrl-B",
+	"forward":        "Ctrl-F",
+	"find":           "Ctrl-S",
+	"half_page_down": "Ctrl-V",
+	"half_page_up":   "Alt-v",
+	"top":            "<",
+	"bottom":         ">",
+})
+
+// keyPresets are the sets of bindings ui.keymap picks from, before the
+// overrides of the keys section
+var keyPresets = map[string]map[string]string{
+	"vim":   defaultKeys,
+	"emacs": emacsKeys,
+}
+
+// reservedKeys keep their meaning on every page and cannot be bound
+var reservedKeys = map[tcell.Key]bool{
+	tcell.KeyEnter:   true,
+	tcell.KeyEscape:  true,
+	tcell.KeyTab:     true,
+	tcell.KeyBacktab: true,
+	tcell.KeyUp:      true,
+	tcell.KeyDown:    true,
+	tcell.KeyLeft:    true,
+	tcell.KeyRight:   true,
+}
+
+// findActions only apply while finding inside an entry, so they may share
+// their keys with other actions
+var findActions = map[string]bool{
+	"find_next":     true,
+	"find_previous": true,
+}
+
+func overrideKeys(keys, overrides map[string]string) map[string]string {
+	out := maps.Clone(keys)
+	maps.Copy(out, overrides)
+	return out
+}
+
+// parseKey reads a binding: a single character, optionally after Alt- as in
+// "Alt-v", or the name of a special key as tcell spells it, e.g. "Ctrl-N",
+// "F2" or "PgDn", in any case
+func parseKey(spec string) (keyBinding, bool) {
+	if r := []rune(spec); len(r) == 1 {
+		return keyBinding{key: tcell.KeyRune, ch: r[0]}, true
+	}
+	if len(spec) > len(altPrefix) && strings.EqualFold(spec[:len(altPrefix)], altPrefix) {
+		if r := []rune(spec[len(altPrefix):]); len(r) == 1 {
+			return keyBinding{key: tcell.KeyRune, ch: r[0], alt: true}, true
+		}
+	}
+	for key, name := range tcell.KeyNames {
+		if strings.EqualFold(name, spec) {
+			return keyBinding{key: key}, true
+		}
+	}
+	return keyBinding{}, false
+}
+
+// bindingOf returns the binding event matches, ignoring modifiers other than
+// Alt on runes and those making up special keys
+func bindingOf(event *tcell.EventKey) keyBinding {
+	if event.Key() == tcell.KeyRune {
+		return keyBinding{key: tcell.KeyRune, ch: event.Rune(), alt: event.Modifiers()&tcell.ModAlt != 0}
+	}
+	return keyBinding{key: event.Key()}
+}
+
+func (b keyBinding) String() string {
+	if b.key == tcell.KeyRune && b.alt {
+		return altPrefix + string(b.ch)
+	}
+	if b.key == tcell.KeyRune {
+		return string(b.ch)
+	}
+	if name, ok := tcell.KeyNames[b.key]; ok {
+		return name
+	}
+	return fmt.Sprintf("Key[%d]", b.key)
+}
+
+// keyBindings returns the key of every action: the preset of ui.keymap
+// overridden by the keys section
+func (c *Config) keyBindings() map[string]string {
+	preset, ok := keyPresets[c.UI.Keymap]
+	if !ok {
+		preset = defaultKeys
+	}
+	return overrideKeys(preset, c.Keys)
+}
@@ -0,0 +1,495 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	rae "github.com/rae-api-com/go-rae"
+)
+
+const (
+	// lemmaMaxCandidates bounds the lookups spent on resolving a form
+	lemmaMaxCandidates = 8
+	// lemmaMaxMisses bounds the misses remembered, the set starts over once full
+	lemmaMaxMisses = 4096
+)
+
+// lemmaMisses remembers the forms that resolved to no entry and the
+// candidates the dictionary does not know, so that retyping a word never
+// spends the lookups again
+var lemmaMisses = struct {
+	mu    sync.Mutex
+	forms map[string]bool
+	words map[string]bool
+}{forms: map[string]bool{}, words: map[string]bool{}}
+
+func lemmaMissed(set map[string]bool, key string) bool {
+	lemmaMisses.mu.Lock()
+	defer lemmaMisses.mu.Unlock()
+	return set[cacheKey(key)]
+}
+
+func addLemmaMiss(set map[string]bool, key string) {
+	lemmaMisses.mu.Lock()
+	defer lemmaMisses.mu.Unlock()
+	if len(set) >= lemmaMaxMisses {
+		clear(set)
+	}
+	set[cacheKey(key)] = true
+}
+
+// lemmaMatch is an inflected form resolved to its dictionary entry
+type lemmaMatch struct {
+	Form  string
+	Lemma string
+	Entry rae.WordEntry
+	// Analyses describe what Form is, e.g. "presente de indicativo (yo)"
+	Analyses []string
+}
+
+// Describe returns a one line explanation such as
+// «tuvieron» es una forma de «tener»: pretérito perfecto simple de indicativo (ellos, ellas)
+func (m lemmaMatch) Describe() string {
+	return trf("«%s» es una forma de «%s»: %s", m.Form, m.Lemma, strings.Join(m.Analyses, "; "))
+}
+
+// lemmaCandidate is a possible dictionary form of an inflected word. Verbs
+// are confirmed against their paradigm, nominal forms only by existing
+type lemmaCandidate struct {
+	lemma string
+	// nominal describes a nominal inflection, e.g. "plural"; empty for verbs
+	nominal string
+	// cost is the number of rules applied, cheaper candidates are tried first
+	cost int
+}
+
+// Regular endings, without accents as forms are matched folded
+var (
+	arEndings = strings.Fields(`o as a amos ais an aba abas abamos abais aban e aste asteis aron
+		are aras ara aremos areis aran aria arias ariamos ariais arian es emos eis en
+		aramos arais ase ases asemos aseis asen ares aren ad ando ado ada ados adas`)
+	erEndings = strings.Fields(`o es e emos eis en ia ias iamos iais ian i iste io imos isteis ieron
+		ere eras era eremos ereis eran eria erias eriamos eriais erian a as amos ais an
+		iera ieras ieramos ierais ieran iese ieses iesemos ieseis iesen iere ieres ieremos iereis ieren
+		ed iendo ido ida idos idas yendo yo yeron yera yeras yeramos yerais yeran yese yeses yesemos yeseis yesen`)
+	irEndings = strings.Fields(`o es e imos is en ia ias iamos iais ian i iste io isteis ieron
+		ire iras ira iremos ireis iran iria irias iriamos iriais irian a as amos ais an
+		iera ieras ieramos ierais ieran iese ieses iesemos ieseis iesen iere ieres ieremos iereis ieren
+		id iendo ido ida idos idas yendo yo yeron yera yeras yeramos yerais yeran yese yeses yesemos yeseis yesen`)
+
+	// strongEndings follow the irregular preterite stems, e.g. tuv-ieron
+	strongEndings = strings.Fields(`e iste o imos isteis ieron eron
+		iera ieras ieramos ierais ieran iese ieses iesemos ieseis iesen iere ieres ieremos iereis ieren
+		era eras eramos erais eran ese eses esemos eseis esen`)
+	// futureEndings follow the irregular future and conditional stems
+	futureEndings = strings.Fields(`e as a emos eis an ia ias iamos iais ian`)
+
+	// stemStressedEndings are the endings of the present and the imperative
+	// that leave the stress on the root
+	stemStressedEndings = strings.Fields(`o as a an e es en`)
+)
+
+// strongStems and futureStems map irregular stems to their infinitive. They
+// also match at the end of a longer stem, so obtuv- gives obtener
+var (
+	strongStems = map[string][]string{
+		"tuv": {"tener"}, "estuv": {"estar"}, "anduv": {"andar"}, "hub": {"haber"},
+		"pud": {"poder"}, "pus": {"poner"}, "sup": {"saber"}, "cup": {"caber"},
+		"quis": {"querer"}, "vin": {"venir"}, "hic": {"hacer"}, "hiz": {"hacer"},
+		"dij": {"decir"}, "traj": {"traer"}, "duj": {"ducir"}, "fu": {"ser", "ir"},
+	}
+	futureStems = map[string][]string{
+		"tendr": {"tener"}, "pondr": {"poner"}, "saldr": {"salir"}, "valdr": {"valer"},
+		"vendr": {"venir"}, "habr": {"haber"}, "podr": {"poder"}, "sabr": {"saber"},
+		"cabr": {"caber"}, "querr": {"querer"}, "har": {"hacer"}, "dir": {"decir"},
+	}
+)
+
+// suppletiveForms are forms sharing no stem with their infinitive
+var suppletiveForms = func() map[string][]string {
+	forms := make(map[string][]string)
+	add := func(lemma, list string) {
+		for _, form := range strings.Fields(list) {
+			forms[form] = append(forms[form], lemma)
+		}
+	}
+	add("ser", "soy eres es somos sois son era eras eramos erais eran sea seas seamos seais sean")
+	add("ir", "voy vas va vamos vais van iba ibas ibamos ibais iban vaya vayas vayamos vayais vayan ve")
+	add("haber", "he has ha hemos habeis han hay haya hayas hayamos hayais hayan")
+	add("estar", "estoy estas esta estamos estais estan este estes esten")
+	add("dar", "doy di diste dio dimos disteis dieron de des demos deis den")
+	add("ver", "veo vi viste vio vimos visteis vieron vea veas veamos veais vean ve")
+	add("saber", "se sepa sepas sepamos sepais sepan")
+	add("caber", "quepo quepa quepas quepamos quepais quepan")
+	return forms
+}()
+
+// foldAccents lowercases s and removes its acute accents and diaeresis
+func foldAccents(s string) string {
+	return strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u").
+		Replace(strings.ToLower(strings.TrimSpace(s)))
+}
+
+// stemVariants returns the stems an inflected stem may come from, undoing
+// the spelling changes before ending and the vowel changes of the root, with
+// the number of unlikely changes undone
+func stemVariants(stem, class, ending string) map[string]int {
+	variants := map[string]int{stem: 0}
+
+	beforeE := strings.HasPrefix(ending, "e")
+	beforeAO := strings.HasPrefix(ending, "a") || strings.HasPrefix(ending, "o")
+	var spelling [][2]string
+	switch {
+	case class == "ar" && beforeE:
+		spelling = [][2]string{{"qu", "c"}, {"gu", "g"}, {"c", "z"}}
+	case class != "ar" && beforeAO:
+		spelling = [][2]string{{"zc", "c"}, {"z", "c"}, {"j", "g"}, {"g", "gu"}, {"ng", "n"}, {"lg", "l"}, {"ig", ""}}
+	}
+	// The spelling keeps the sound of the root, so it is undone for free
+	for _, rule := range spelling {
+		if strings.HasSuffix(stem, rule[0]) {
+			variants[strings.TrimSuffix(stem, rule[0])+rule[1]] = 0
+		}
+	}
+	if class == "ir" && strings.HasSuffix(stem, "uy") {
+		variants[strings.TrimSuffix(stem, "y")] = 0
+	}
+
+	// Vowel changes of the root: quier-o, jueg-a, pid-o, durm-ió. Forms
+	// stressed on the root are as likely to come from a verb that breaks its
+	// vowel into ie or ue as from a regular one, so undoing it is free there,
+	// and so is closing the e or o of -ir verbs before an ending with no
+	// stressed i
+	stressed := slices.Contains(stemStressedEndings, ending)
+	closes := class == "ir" && (stressed || ending == "amos" || ending == "ais" ||
+		strings.HasPrefix(ending, "ie") || strings.HasPrefix(ending, "io"))
+	for base, cost := range copyCosts(variants) {
+		for _, rule := range []struct {
+			from, to string
+			free     bool
+		}{
+			{"ie", "e", stressed}, {"ue", "o", stressed}, {"ue", "u", stressed},
+			{"i", "e", closes}, {"u", "o", closes},
+		} {
+			// Only -ir verbs close their root vowel
+			if len(rule.from) == 1 && class != "ir" {
+				continue
+			}
+			i := strings.LastIndex(base, rule.from)
+			if i <= 0 || i+len(rule.from) == len(base) && rule.from != "ie" {
+				continue
+			}
+			// The i of ie and the u of ue are undone as diphthongs, and the u of
+			// qu and gu is no vowel
+			if len(rule.from) == 1 && strings.HasPrefix(base[i+1:], "e") ||
+				rule.from == "u" && (base[i-1] == 'q' || base[i-1] == 'g') {
+				continue
+			}
+			change := 1
+			if rule.free {
+				change = 0
+			}
+			variant := base[:i] + rule.to + base[i+len(rule.from):]
+			if _, ok := variants[variant]; !ok {
+				variants[variant] = cost + change
+			}
+		}
+	}
+	return variants
+}
+
+// longestFirst returns the keys of stems, the most specific first
+func longestFirst(stems map[string][]string) []string {
+	keys := make([]string, 0, len(stems))
+	for k := range stems {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+func copyCosts(m map[string]int) map[string]int {
+	out := make(map[string]int, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// lemmaCandidates returns the possible dictionary forms of form, most likely
+// first
+func lemmaCandidates(form string) []lemmaCandidate {
+	folded := foldAccents(form)
+	seen := map[string]bool{folded: true}
+	var candidates []lemmaCandidate
+	add := func(lemma, nominal string, cost int) {
+		// Infinitives in -eír and -oír keep their accent
+		if nominal == "" && (strings.HasSuffix(lemma, "eir") || strings.HasSuffix(lemma, "oir")) {
+			lemma = strings.TrimSuffix(lemma, "ir") + "ír"
+		}
+		if lemma == "" || seen[lemma] {
+			return
+		}
+		seen[lemma] = true
+		candidates = append(candidates, lemmaCandidate{lemma: lemma, nominal: nominal, cost: cost})
+	}
+
+	for _, lemma := range suppletiveForms[folded] {
+		add(lemma, "", 0)
+	}
+	irregular := func(stems map[string][]string, endings []string) {
+		for _, ending := range endings {
+			stem, ok := strings.CutSuffix(folded, ending)
+			if !ok {
+				continue
+			}
+			for _, irregularStem := range longestFirst(stems) {
+				if prefix, ok := strings.CutSuffix(stem, irregularStem); ok {
+					for _, lemma := range stems[irregularStem] {
+						add(prefix+lemma, "", 0)
+					}
+				}
+			}
+		}
+	}
+	irregular(strongStems, strongEndings)
+	irregular(futureStems, futureEndings)
+
+	for _, c := range nominalCandidates(strings.ToLower(strings.TrimSpace(form))) {
+		add(c.lemma, c.nominal, c.cost)
+	}
+
+	var regular []lemmaCandidate
+	// Classes go from the most to the least common, -ar verbs being the
+	// great majority
+	for rank, class := range []struct {
+		suffix  string
+		endings []string
+	}{{"ar", arEndings}, {"er", erEndings}, {"ir", irEndings}} {
+		for _, ending := range class.endings {
+			stem, ok := strings.CutSuffix(folded, ending)
+			if !ok || utf8.RuneCountInString(stem) < 1 {
+				continue
+			}
+			for variant, cost := range stemVariants(stem, class.suffix, ending) {
+				// Longer endings are more specific, so they are preferred
+				regular = append(regular, lemmaCandidate{
+					lemma: variant + class.suffix,
+					cost:  cost*100 + rank*10 - len(ending),
+				})
+			}
+		}
+	}
+	sort.SliceStable(regular, func(i, j int) bool {
+		if regular[i].cost != regular[j].cost {
+			return regular[i].cost < regular[j].cost
+		}
+		return regular[i].lemma < regular[j].lemma
+	})
+	for _, c := range regular {
+		add(c.lemma, "", 1)
+	}
+
+	if len(candidates) > lemmaMaxCandidates {
+		candidates = candidates[:lemmaMaxCandidates]
+	}
+	return candidates
+}
+
+// nominalCandidates undoes the plural and feminine of nouns and adjectives
+func nominalCandidates(form string) []lemmaCandidate {
+	var out []lemmaCandidate
+	singular := func(s string) []string {
+		switch {
+		case strings.HasSuffix(s, "ces"):
+			return []string{strings.TrimSuffix(s, "ces") + "z"}
+		case strings.HasSuffix(s, "es"):
+			stem := strings.TrimSuffix(s, "es")
+			// canciones, jóvenes: the accent moves between singular and plural
+			return []string{stem, accentLastVowel(stem), foldAccents(stem), strings.TrimSuffix(s, "s")}
+		case strings.HasSuffix(s, "s"):
+			return []string{strings.TrimSuffix(s, "s")}
+		}
+		return nil
+	}
+	masculine := func(s string) []string {
+		switch {
+		case strings.HasSuffix(s, "ora"):
+			return []string{strings.TrimSuffix(s, "a")}
+		case strings.HasSuffix(s, "a"):
+			return []string{strings.TrimSuffix(s, "a") + "o"}
+		}
+		return nil
+	}
+
+	for _, s := range singular(form) {
+		out = append(out, lemmaCandidate{lemma: s, nominal: "plural", cost: 1})
+		for _, m := range masculine(s) {
+			out = append(out, lemmaCandidate{lemma: m, nominal: "femenino plural", cost: 2})
+		}
+	}
+	for _, m := range masculine(form) {
+		out = append(out, lemmaCandidate{lemma: m, nominal: "femenino", cost: 1})
+	}
+	return out
+}
+
+// accentLastVowel writes the accent of singulars ending in -ón, -án, -én,
+// -ín and -ún, e.g. cancion from canciones gives canción
+func accentLastVowel(stem string) string {
+	accented := map[string]string{"an": "án", "en": "én", "in": "ín", "on": "ón", "un": "ún"}
+	for plain, acc := range accented {
+		if strings.HasSuffix(stem, plain) {
+			return strings.TrimSuffix(stem, plain) + acc
+		}
+	}
+	return stem
+}
+
+// formAnalyses describes every place of the paradigm c where form appears,
+// gathering the persons sharing a tense, e.g. "presente de subjuntivo
+// (ustedes; ellos, ellas)"
+func formAnalyses(c *rae.Conjugations, form string) []string {
+	folded := foldAccents(form)
+	var tenses []string
+	persons := make(map[string][]string)
+
+	for _, table := range conjugationTables(c, conjugationFilter{}) {
+		for _, f := range table.Forms {
+			// Some tenses list alternatives, e.g. "amara o amase"
+			for _, alt := range strings.Split(f.Form, " o ") {
+				if foldAccents(alt) != folded {
+					continue
+				}
+				tense := describeTense(table)
+				if table.Mood == "non_personal" {
+					tense = f.label
+				}
+				if _, ok := persons[tense]; !ok {
+					tenses = append(tenses, tense)
+					persons[tense] = nil
+				}
+				if table.Mood != "non_personal" && !slices.Contains(persons[tense], f.label) {
+					persons[tense] = append(persons[tense], f.label)
+				}
+			}
+		}
+	}
+
+	analyses := make([]string, 0, len(tenses))
+	for _, tense := range tenses {
+		if len(persons[tense]) == 0 {
+			analyses = append(analyses, tense)
+			continue
+		}
+		analyses = append(analyses, fmt.Sprintf("%s (%s)", tense, strings.Join(persons[tense], "; ")))
+	}
+	return analyses
+}
+
+func describeTense(table conjugationTable) string {
+	if table.Mood == "imperative" {
+		return "imperativo"
+	}
+	mood := strings.TrimPrefix(strings.ToLower(table.moodLabel), "modo ")
+	return fmt.Sprintf("%s de %s", strings.ToLower(table.tenseLabel), mood)
+}
+
+// confirmLemma checks that form belongs to entry, the one of candidate
+func confirmLemma(form string, candidate lemmaCandidate, entry rae.WordEntry) (lemmaMatch, bool) {
+	match := lemmaMatch{Form: form, Lemma: entry.Word, Entry: entry}
+	if match.Lemma == "" {
+		match.Lemma = candidate.lemma
+	}
+
+	if candidate.nominal != "" {
+		match.Analyses = []string{candidate.nominal}
+		return match, true
+	}
+
+	conjugations := entryConjugations(entry)
+	if conjugations == nil {
+		return lemmaMatch{}, false
+	}
+	match.Analyses = formAnalyses(conjugations, form)
+	return match, len(match.Analyses) > 0
+}
+
+// lemmatize resolves an inflected form, e.g. "tuvieron" or "gatas", to the
+// entry of its dictionary form. Candidates are looked up one at a time, most
+// likely first, until a verb is confirmed, a nominal form only winning when
+// no verb is. Misses are remembered unless a lookup failed for another reason
+// than the word being unknown
+func lemmatize(ctx context.Context, cli dictionary, form string) (lemmaMatch, bool) {
+	if lemmaMissed(lemmaMisses.forms, form) {
+		return lemmaMatch{}, false
+	}
+
+	certain := true
+	var nominal *lemmaMatch
+	for _, candidate := range lemmaCandidates(form) {
+		if ctx.Err() != nil {
+			return lemmaMatch{}, false
+		}
+		if nominal != nil && candidate.nominal != "" || lemmaMissed(lemmaMisses.words, candidate.lemma) {
+			continue
+		}
+
+		entry, err := cli.Word(ctx, candidate.lemma)
+		if err != nil {
+			if isNotFound(entry, err) {
+				addLemmaMiss(lemmaMisses.words, candidate.lemma)
+			} else {
+				certain = false
+			}
+			continue
+		}
+		match, ok := confirmLemma(form, candidate, entry)
+		if !ok {
+			continue
+		}
+		if candidate.nominal == "" {
+			return match, true
+		}
+		// A form that is also a verb form is read as the verb, e.g. hablas
+		// as hablar rather than the plural of habla
+		if nominal == nil {
+			nominal = &match
+		}
+	}
+
+	if nominal != nil {
+		return *nominal, true
+	}
+	if certain {
+		addLemmaMiss(lemmaMisses.forms, form)
+	}
+	return lemmaMatch{}, false
+}
+
+// lemmaSource returns the dictionary the candidates of a form are looked up
+// in when looking the form up failed with err: the offline store alone after
+// an offline miss, since the API was just found unreachable
+func lemmaSource(cli dictionary, err error) dictionary {
+	if c, ok := cli.(*offlineClient); ok && errors.Is(err, errOfflineMiss) {
+		return c.local()
+	}
+	return cli
+}
+
+// shouldLemmatize reports whether a failed lookup is worth resolving as an
+// inflected form: the word is unknown, not the API unreachable
+func shouldLemmatize(entry rae.WordEntry, err error) bool {
+	return isNotFound(entry, err) || errors.Is(err, errOfflineMiss)
+}
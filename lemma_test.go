@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	rae "github.com/rae-api-com/go-rae"
+)
+
+func TestLemmaCandidates(t *testing.T) {
+	tests := []struct {
+		form    string
+		lemma   string
+		nominal string
+	}{
+		{form: "soy", lemma: "ser"},
+		{form: "fueron", lemma: "ir"},
+		{form: "tuvieron", lemma: "tener"},
+		{form: "obtuvimos", lemma: "obtener"},
+		{form: "tendremos", lemma: "tener"},
+		{form: "cantaban", lemma: "cantar"},
+		{form: "comieron", lemma: "comer"},
+		{form: "vivimos", lemma: "vivir"},
+		{form: "busqué", lemma: "buscar"},
+		{form: "quieres", lemma: "querer"},
+		{form: "juegan", lemma: "jugar"},
+		{form: "durmió", lemma: "dormir"},
+		{form: "hablas", lemma: "hablar"},
+		{form: "amas", lemma: "amar"},
+		{form: "casas", lemma: "casa", nominal: "plural"},
+		{form: "canciones", lemma: "canción", nominal: "plural"},
+		{form: "luces", lemma: "luz", nominal: "plural"},
+		{form: "niña", lemma: "niño", nominal: "femenino"},
+		{form: "niñas", lemma: "niño", nominal: "femenino plural"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.form, func(t *testing.T) {
+			candidates := lemmaCandidates(tt.form)
+			for _, c := range candidates {
+				if c.lemma == tt.lemma && c.nominal == tt.nominal {
+					return
+				}
+			}
+			t.Errorf("no candidate %q (%q) among %v", tt.lemma, tt.nominal, candidates)
+		})
+	}
+}
+
+func TestDescribeTense(t *testing.T) {
+	tests := []struct {
+		mood, tense string
+		want        string
+	}{
+		{mood: "indicative", tense: "present", want: "presente de indicativo"},
+		{mood: "indicative", tense: "preterite", want: "pretérito perfecto simple de indicativo"},
+		{mood: "subjunctive", tense: "imperfect", want: "pretérito imperfecto de subjuntivo"},
+		{mood: "imperative", want: "imperativo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			for _, table := range conjugationTables(sampleConjugations(), conjugationFilter{}) {
+				if table.Mood == tt.mood && table.Tense == tt.tense {
+					if got := describeTense(table); got != tt.want {
+						t.Errorf("describeTense = %q, want %q", got, tt.want)
+					}
+					return
+				}
+			}
+			t.Fatalf("no table %s/%s", tt.mood, tt.tense)
+		})
+	}
+}
+
+func TestFormAnalyses(t *testing.T) {
+	tests := []struct {
+		form string
+		want []string
+	}{
+		{form: "amo", want: []string{"presente de indicativo (yo)"}},
+		{form: "amáramos", want: []string{"pretérito imperfecto de subjuntivo (nosotros, nosotras)"}},
+		{form: "ame", want: []string{"presente de subjuntivo (yo; usted; él, ella)"}},
+		{form: "amado", want: []string{"participio"}},
+		{form: "amor", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.form, func(t *testing.T) {
+			if got := formAnalyses(sampleConjugations(), tt.form); !slices.Equal(got, tt.want) {
+				t.Errorf("formAnalyses = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// mapDictionary knows the entries it holds and no other word
+type mapDictionary map[string]rae.WordEntry
+
+func (d mapDictionary) Word(_ context.Context, word string) (rae.WordEntry, error) {
+	if entry, ok := d[word]; ok {
+		return entry, nil
+	}
+	return rae.WordEntry{Word: word}, errors.New("palabra no encontrada")
+}
+
+func (d mapDictionary) Search(context.Context, string) ([]rae.SearchResult, error) {
+	return nil, nil
+}
+
+func TestLemmatizePrefersVerbs(t *testing.T) {
+	noun := rae.WordEntry{Word: "ama", Meanings: []rae.Meaning{{}}}
+	verb := rae.WordEntry{Word: "amar", Meanings: []rae.Meaning{{Conjugations: sampleConjugations()}}}
+
+	tests := []struct {
+		name  string
+		dict  mapDictionary
+		lemma string
+	}{
+		{name: "noun and verb", dict: mapDictionary{"ama": noun, "amar": verb}, lemma: "amar"},
+		{name: "noun only", dict: mapDictionary{"ama": noun}, lemma: "ama"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clear(lemmaMisses.forms)
+			clear(lemmaMisses.words)
+			match, ok := lemmatize(context.Background(), tt.dict, "amas")
+			if !ok || match.Lemma != tt.lemma {
+				t.Errorf("lemmatize(amas) = %q, %v, want %q", match.Lemma, ok, tt.lemma)
+			}
+		})
+	}
+}
+
+// sampleConjugations is part of the paradigm of amar
+func sampleConjugations() *rae.Conjugations {
+	var c rae.Conjugations
+	c.ConjugationNonPersonal.Infinitive = "amar"
+	c.ConjugationNonPersonal.Participle = "amado"
+	c.ConjugationIndicative.Present = rae.Conjugation{SingularFirstPerson: "amo", SingularSecondPerson: "amas"}
+	c.ConjugationIndicative.Preterite = rae.Conjugation{SingularSecondPerson: "amaste"}
+	c.ConjugationSubjunctive.Present = rae.Conjugation{
+		SingularFirstPerson:        "ame",
+		SingularFormalSecondPerson: "ame",
+		SingularThirdPerson:        "ame",
+	}
+	c.ConjugationSubjunctive.Imperfect = rae.Conjugation{PluralFirstPerson: "amáramos o amásemos"}
+	c.ConjugationImperative.SingularSecondPerson = "ama"
+	return &c
+}
+
+// downDictionary counts the lookups failing as the API cannot be reached
+type downDictionary struct {
+	lookups int
+}
+
+func (d *downDictionary) Word(context.Context, string) (rae.WordEntry, error) {
+	d.lookups++
+	return rae.WordEntry{}, errors.New("sin conexión")
+}
+
+func (d *downDictionary) Search(context.Context, string) ([]rae.SearchResult, error) {
+	return nil, errors.New("sin conexión")
+}
+
+func TestLemmatizeOfflineMiss(t *testing.T) {
+	clear(lemmaMisses.forms)
+	clear(lemmaMisses.words)
+
+	store := newOfflineStore(filepath.Join(t.TempDir(), "offline.jsonl"))
+	verb := rae.WordEntry{Word: "amar", Meanings: []rae.Meaning{{Conjugations: sampleConjugations()}}}
+	if err := store.Put("amar", verb); err != nil {
+		t.Fatal(err)
+	}
+	api := new(downDictionary)
+
+	doc := lookupJSON(context.Background(), newOfflineClient(api, store), "amas")
+	if doc.Lemma == nil || doc.Lemma.Lemma != "amar" {
+		t.Fatalf("lemma = %+v, want amar", doc.Lemma)
+	}
+	// Only the form itself tried the API, its candidates came from the store
+	if api.lookups != 1 {
+		t.Errorf("API looked up %d times, want 1", api.lookups)
+	}
+}
@@ -3,8 +3,14 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
+	"log"
 	"os"
+	"os/signal"
+	"slices"
 	"strings"
+	"syscall"
+	"time"
 
 	rae "github.com/rae-api-com/go-rae"
 	"github.com/sonirico/vago/fp"
@@ -20,84 +26,469 @@ var (
 	buildTime = "unknown"
 )
 
-type args struct {
-	word fp.Option[string]
-	tui  bool
+// environment is what commands need once the configuration is resolved
+type environment struct {
+	ctx     context.Context
+	cfg     Config
+	cfgPath string
+	format  outputFormat
+	cache   *diskCache
+	store   *offlineStore
+	// rateLimit caps the API requests started per second, 0 meaning no limit
+	rateLimit float64
 }
 
-func printHelp() {
-	fmt.Println("RAE Dictionary CLI")
-	fmt.Println("\nUsage:")
-	fmt.Println("  rae-tui [WORD]        - Search for a word in RAE dictionary in CLI mode")
-	fmt.Println("  rae-tui tui [WORD]    - Open the TUI interface with optional initial word")
-	fmt.Println("\nOptions:")
-	fmt.Println("  -h, --help            - Display this help message")
-	fmt.Println("  -v, --version         - Display version information")
-	fmt.Println("\nExamples:")
-	fmt.Println("  rae-tui hola          - Show definition of 'hola' in CLI mode")
-	fmt.Println("  rae-tui tui           - Open TUI interface")
-	fmt.Println("  rae-tui tui casa      - Open TUI interface and search for 'casa'")
-	os.Exit(0)
+func newEnvironment(ctx context.Context, cfg Config, cfgPath string) *environment {
+	return &environment{
+		ctx:     ctx,
+		cfg:     cfg,
+		cfgPath: cfgPath,
+		format:  outputFormat(cfg.Format),
+		cache:   newDiskCache(cfg.cacheConfig()),
+		store:   newOfflineStore(defaultOfflineStorePath()),
+	}
+}
+
+// dictionary returns the client for lookups: the API behind the cache, with
+// the offline dictionary as fallback
+func (e *environment) dictionary() dictionary {
+	var cli dictionary
+	if !e.cfg.Offline {
+		cli = rae.New(rae.WithVersion(e.cfg.API.Version), rae.WithTimeout(e.cfg.API.Timeout))
+		if e.rateLimit > 0 {
+			cli = newRateLimitedClient(cli, e.rateLimit)
+		}
+		if e.cfg.Cache.Enabled {
+			cli = newCachedClient(cli, e.cache)
+		}
+	}
+	return newOfflineClient(cli, e.store)
+}
+
+func runLookup(env *environment, inv invocation) int {
+	cli := env.dictionary()
+	word := strings.TrimSpace(inv.args[0])
+
+	switch env.format {
+	case formatJSON:
+		return renderJSON(env.ctx, cli, word, os.Stdout)
+	default:
+		return renderNoTUI(env.ctx, cli, word, env.cfg.UI.PreviewLength)
+	}
+}
+
+func runTUI(env *environment, inv invocation) int {
+	word := fp.None[string]()
+	if len(inv.args) > 0 {
+		word = fp.Some(strings.TrimSpace(inv.args[0]))
+	}
+
+	// Corrupt data files must not prevent the TUI from starting, but they
+	// are left untouched instead of being overwritten
+	opts := []TuiOption{WithConfig(env.cfg)}
+	if history, err := loadHistory(defaultHistoryPath()); err == nil {
+		opts = append(opts, WithHistory(history))
+	}
+	if favorites, err := loadFavorites(defaultFavoritesPath()); err == nil {
+		opts = append(opts, WithFavorites(favorites))
+	}
+	if deck, err := loadStudyDeck(defaultStudyPath()); err == nil {
+		opts = append(opts, WithStudy(deck))
+	}
+
+	NewTUI(env.dictionary(), opts...).Run(env.ctx, word)
+	return exitFound
+}
+
+func runSearch(env *environment, inv invocation) int {
+	terms := strings.TrimSpace(strings.Join(inv.args, " "))
+	return renderSearch(env.ctx, env.dictionary(), terms, env.format, inv.opts.limit, env.cfg.UI.PreviewLength)
 }
 
-func printVersion() {
-	fmt.Printf("rae-tui %s\n", version)
-	fmt.Printf("commit: %s\n", commit)
-	fmt.Printf("built: %s\n", buildTime)
-	os.Exit(0)
+func runConjugate(env *environment, inv invocation) int {
+	filter, err := newConjugationFilter(inv.opts.moods, inv.opts.tenses, inv.opts.persons)
+	if err != nil {
+		return usageFailure("conjugate", "%v", err)
+	}
+	return renderConjugation(env.ctx, env.dictionary(), strings.TrimSpace(inv.args[0]), filter, env.format)
 }
 
-func parseArgs() args {
-	// Check for version flag
-	for _, arg := range os.Args[1:] {
-		if arg == "-v" || arg == "--version" || arg == "version" {
-			printVersion()
-			return args{}
+func runCompare(env *environment, inv invocation) int {
+	doc := compareJSON(env.ctx, env.dictionary(), inv.args[0], inv.args[1])
+	if env.format == formatJSON {
+		if err := writeJSON(os.Stdout, doc); err != nil {
+			fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+			return exitError
 		}
+		return doc.Status.exitCode()
+	}
 
-		if arg == "-h" || arg == "--help" || arg == "help" {
-			printHelp()
-			return args{}
+	if doc.Comparison == nil {
+		for _, side := range []jsonLookup{doc.Left, doc.Right} {
+			if side.Status != statusFound {
+				fmt.Fprintf(os.Stderr, "rae-tui: %s\n", describeMissing(side))
+			}
 		}
+		return doc.Status.exitCode()
+	}
+	renderComparison(os.Stdout, doc, terminalWidth())
+	return exitFound
+}
+
+func runBatch(env *environment, inv invocation) int {
+	opts := inv.opts
+	if opts.jobs < 1 {
+		return usageFailure("batch", "--jobs debe ser al menos 1")
+	}
+	if opts.resume && opts.output == "" {
+		return usageFailure("batch", "--resume necesita --output")
 	}
 
-	if len(os.Args) > 2 {
-		return args{
-			word: fp.Some(strings.TrimSpace(os.Args[2])),
-			tui:  strings.TrimSpace(os.Args[1]) == "tui",
+	in := io.Reader(os.Stdin)
+	if len(inv.args) > 0 && inv.args[0] != "-" {
+		f, err := os.Open(inv.args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+			return exitError
 		}
+		defer f.Close()
+		in = f
+	}
+	words, err := readBatchWords(in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+		return exitError
 	}
 
-	if len(os.Args) > 1 {
-		uniqArg := strings.TrimSpace(os.Args[1])
+	format := env.format
+	if format == formatText {
+		format = formatMarkdown
+	}
+
+	out, header := io.Writer(os.Stdout), true
+	var skipped int
+	if opts.output != "" {
+		flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+		if opts.resume {
+			flags = os.O_CREATE | os.O_RDWR | os.O_APPEND
+		}
+		f, err := os.OpenFile(opts.output, flags, 0o644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+			return exitError
+		}
+		defer f.Close()
+		out = f
 
-		if uniqArg == "tui" {
-			return args{
-				tui: true,
+		if opts.resume {
+			info, err := f.Stat()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+				return exitError
 			}
+			done, err := completedWords(f, format)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+				return exitError
+			}
+			// A file holding just the header has started already
+			header = info.Size() == 0
+			total := len(words)
+			words = slices.DeleteFunc(words, func(w string) bool { return done[w] })
+			skipped = total - len(words)
+		}
+	}
+
+	w, err := newBatchWriter(out, format, header)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+		return exitError
+	}
+
+	// An interruption stops the batch cleanly, keeping what was written
+	ctx, stop := signal.NotifyContext(env.ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	env.rateLimit = opts.rate
+	report, err := lookupBatch(ctx, env.dictionary(), words, opts.jobs, env.cfg.Offline, w)
+	report.skipped = skipped
+	report.resumable = true
+	report.print(os.Stderr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+		return exitError
+	}
+	return report.exitCode()
+}
+
+func runRandom(env *environment, inv invocation) int {
+	return lookupPicked(env, inv, false, time.Time{})
+}
+
+func runWotd(env *environment, inv invocation) int {
+	date := time.Now()
+	if inv.opts.date != "" {
+		var err error
+		if date, err = time.ParseInLocation(time.DateOnly, inv.opts.date, time.Local); err != nil {
+			return usageFailure("wotd", "fecha inválida %q, usa AAAA-MM-DD", inv.opts.date)
 		}
+	}
+	return lookupPicked(env, inv, true, date)
+}
 
-		return args{
-			word: fp.Some(uniqArg),
-			tui:  false,
+// lookupPicked looks up the word of date, or a random one, from the source
+// given on the command line or configured
+func lookupPicked(env *environment, inv invocation, daily bool, date time.Time) int {
+	source := env.cfg.Wotd.Source
+	if inv.opts.source != "" {
+		if err := validWordSource(inv.opts.source); err != nil {
+			return usageFailure(inv.cmd.name, "%v", err)
 		}
+		source = inv.opts.source
+	}
+
+	word, err := pickWord(source, daily, date)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+		return exitError
 	}
 
-	return args{
-		word: fp.None[string](),
-		tui:  true,
+	cli := env.dictionary()
+	if env.format == formatJSON {
+		return renderJSON(env.ctx, cli, word, os.Stdout)
 	}
+	if daily {
+		fmt.Printf(tr("%sPalabra del día · %s%s\n"), Bold, date.Format(time.DateOnly), Reset)
+	}
+	return renderNoTUI(env.ctx, cli, word, env.cfg.UI.PreviewLength)
 }
 
-func main() {
-	ctx := context.Background()
-	cli := rae.New(rae.WithVersion(version))
+func runStudy(env *environment, inv invocation) int {
+	deck, err := loadStudyDeck(defaultStudyPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+		return exitError
+	}
+	if inv.opts.stats {
+		return runStudyStats(deck, env.format)
+	}
+	if env.format != formatText {
+		return usageFailure("study", "--format %s solo se admite con --stats", env.format)
+	}
 
-	arguments := parseArgs()
+	if inv.opts.source != "" {
+		if err := validWordSource(inv.opts.source); err != nil {
+			return usageFailure("study", "%v", err)
+		}
+		env.cfg.Study.Source = inv.opts.source
+	}
 
-	if arguments.tui {
-		NewTUI(cli).Run(ctx, arguments.word)
+	opts := []TuiOption{WithConfig(env.cfg), WithStudy(deck), StartStudying()}
+	if history, err := loadHistory(defaultHistoryPath()); err == nil {
+		opts = append(opts, WithHistory(history))
+	}
+	if favorites, err := loadFavorites(defaultFavoritesPath()); err == nil {
+		opts = append(opts, WithFavorites(favorites))
+	}
+
+	NewTUI(env.dictionary(), opts...).Run(env.ctx, fp.None[string]())
+	return exitFound
+}
+
+func runExport(env *environment, inv invocation) int {
+	opts := inv.opts
+	if opts.jobs < 1 {
+		return usageFailure("export", "--jobs debe ser al menos 1")
+	}
+	source := wordSourceFavorites
+	if opts.source != "" {
+		if err := validWordSource(opts.source); err != nil {
+			return usageFailure("export", "%v", err)
+		}
+		source = opts.source
+	}
+
+	format := env.format
+	if format == formatText {
+		format = formatMarkdown
+	}
+
+	// The favorites provide the tags of the Anki notes whatever the source
+	favorites, err := loadFavorites(defaultFavoritesPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+		return exitError
+	}
+
+	words := inv.args
+	if len(words) == 0 {
+		switch {
+		case source == wordSourceFavorites && len(opts.tags) > 0:
+			for _, item := range favorites.List(normalizeTags(opts.tags)...) {
+				words = append(words, item.Word)
+			}
+		case source == wordSourceHistory:
+			var history *lookupHistory
+			history, err = loadHistory(defaultHistoryPath())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+				return exitError
+			}
+			words, err = sourceWords(source, history, nil)
+		default:
+			words, err = sourceWords(source, nil, favorites)
+		}
+		if err == nil && len(words) == 0 {
+			err = errNoWords
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+			return exitError
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(env.ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	env.rateLimit = opts.rate
+	collector := newExportCollector(favorites)
+	report, err := lookupBatch(ctx, env.dictionary(), words, opts.jobs, env.cfg.Offline, collector)
+	report.print(os.Stderr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+		return exitError
+	}
+	if report.canceled || len(collector.entries) == 0 {
+		return report.exitCode()
+	}
+
+	if opts.output != "" {
+		err = exportToFile(opts.output, collector.entries, format)
 	} else {
-		renderNoTUI(ctx, cli, arguments.word.UnwrapUnsafe())
+		err = exportEntries(os.Stdout, collector.entries, format)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+		return exitError
+	}
+	return report.exitCode()
+}
+
+func runServe(env *environment, inv invocation) int {
+	addr := env.cfg.Server.Listen
+	if inv.opts.listen != "" {
+		addr = inv.opts.listen
+	}
+
+	ctx, stop := signal.NotifyContext(env.ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	if err := serve(ctx, addr, newServer(env.dictionary(), logger), logger); err != nil {
+		fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+		return exitError
+	}
+	return exitFound
+}
+
+func runHistory(env *environment, inv invocation) int {
+	history, err := loadHistory(defaultHistoryPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+		return exitError
+	}
+
+	limit := inv.opts.limit
+	if limit == 0 {
+		limit = env.cfg.UI.HistorySize
+	}
+	return runHistoryCommand(history, env.format, limit)
+}
+
+func runFavorites(env *environment, inv invocation) int {
+	favorites, err := loadFavorites(defaultFavoritesPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+		return exitError
 	}
+	return runFavoritesCommand(favorites, env.format, inv.args, inv.opts.tags, inv.opts.note)
+}
+
+func runCache(env *environment, inv invocation) int {
+	return runCacheCommand(env.cache, inv.args)
+}
+
+func runOffline(env *environment, inv invocation) int {
+	return runOfflineCommand(env.store, env.cache, inv.args)
+}
+
+func runConfig(env *environment, inv invocation) int {
+	return runConfigCommand(env.cfg, env.cfgPath, inv.args)
+}
+
+// resolveConfig loads the configuration and applies the command line
+// overrides on top of it
+func resolveConfig(opts options) (Config, string, error) {
+	path, required := opts.config, opts.config != ""
+	if !required {
+		if env := os.Getenv(envPrefix + "CONFIG"); env != "" {
+			path, required = env, true
+		} else {
+			path = defaultConfigPath()
+		}
+	}
+
+	cfg, err := loadConfig(path, required)
+	if err != nil {
+		return cfg, path, err
+	}
+
+	for _, set := range opts.sets {
+		key, value, ok := strings.Cut(set, "=")
+		if !ok {
+			return cfg, path, fmt.Errorf(tr("--set espera CLAVE=VALOR, no %q"), set)
+		}
+		if err := cfg.Set(strings.TrimSpace(key), value); err != nil {
+			return cfg, path, err
+		}
+	}
+	if opts.format != "" {
+		cfg.Format = strings.ToLower(strings.TrimSpace(opts.format))
+	}
+	if opts.noCache {
+		cfg.Cache.Enabled = false
+	}
+	if opts.offline {
+		cfg.Offline = true
+	}
+
+	return cfg, path, cfg.Validate()
+}
+
+func main() {
+	// Until the configuration is read, messages follow the locale
+	setLanguage(languageAuto)
+
+	inv, err := parseArgs(os.Args[1:])
+	if err != nil {
+		name := ""
+		if inv.cmd != nil {
+			name = inv.cmd.name
+		}
+		os.Exit(usageFailure(name, "%v", err))
+	}
+
+	if inv.cmd.standalone {
+		os.Exit(inv.cmd.run(nil, inv))
+	}
+
+	cfg, cfgPath, err := resolveConfig(inv.opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, tr("rae-tui: configuración inválida:\n%v\n"), err)
+		os.Exit(exitError)
+	}
+
+	setLanguage(cfg.UI.Language)
+	setTheme(cfg.themeColors())
+	os.Exit(inv.cmd.run(newEnvironment(context.Background(), cfg, cfgPath), inv))
 }
@@ -0,0 +1,519 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	rae "github.com/rae-api-com/go-rae"
+)
+
+// errOfflineMiss is returned when the API is unreachable and the local store
+// has no entry for the requested word
+var errOfflineMiss error = messageError("sin conexión y la palabra no está en el diccionario local")
+
+// offlineSearchLimit caps the fuzzy results served from the local store
+const offlineSearchLimit = 20
+
+// offlineMaxSize is the size in bytes past which the store file is compacted,
+// dropping the oldest entries if that is not enough
+const offlineMaxSize = 256 << 20
+
+func defaultOfflineStorePath() string {
+	return filepath.Join(dataDir(), "offline.jsonl")
+}
+
+// offlineStore is the local dictionary used when the API cannot be reached. It
+// is a JSON lines file of wordRecord, the same format produced by
+// `rae-tui cache export`, loaded in memory only when a lookup falls back to
+// it. Later lines win, so an update is appended without reading the file and
+// replaces the old entry of its word once the file is compacted, which
+// happens on import and whenever it grows past offlineMaxSize
+type offlineStore struct {
+	path string
+
+	mu      sync.Mutex
+	loaded  bool
+	entries map[string]wordRecord
+	// written holds the words appended by this process, so that looking a word
+	// up again does not append it once more
+	written map[string]bool
+}
+
+func newOfflineStore(path string) *offlineStore {
+	return &offlineStore{path: path}
+}
+
+func (s *offlineStore) load() error {
+	if s.loaded {
+		return nil
+	}
+
+	s.entries = make(map[string]wordRecord)
+
+	f, err := os.Open(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		s.loaded = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := s.merge(f); err != nil {
+		return err
+	}
+
+	s.loaded = true
+	return nil
+}
+
+// merge reads wordRecord lines from r into memory, skipping malformed lines
+func (s *offlineStore) merge(r io.Reader) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16<<20)
+
+	merged := 0
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var rec wordRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			continue
+		}
+		if rec.Key == "" {
+			rec.Key = cacheKey(rec.Entry.Word)
+		}
+		if rec.Key == "" {
+			continue
+		}
+		s.entries[rec.Key] = rec
+		merged++
+	}
+
+	return merged, scanner.Err()
+}
+
+func (s *offlineStore) Get(word string) (rae.WordEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return rae.WordEntry{}, false
+	}
+	rec, ok := s.entries[cacheKey(word)]
+	return rec.Entry, ok
+}
+
+// Put stores entry as the latest one for word, appending it to the file
+// without loading the store. An entry equal to the one held in memory is not
+// appended again
+func (s *offlineStore) Put(word string, entry rae.WordEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := cacheKey(word)
+	if s.written[key] {
+		return nil
+	}
+	if old, ok := s.entries[key]; ok && reflect.DeepEqual(old.Entry, entry) {
+		return nil
+	}
+
+	rec := wordRecord{Key: key, FetchedAt: time.Now(), Entry: entry}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	if s.written == nil {
+		s.written = make(map[string]bool)
+	}
+	s.written[key] = true
+	if s.loaded {
+		s.entries[key] = rec
+	}
+
+	info, err := f.Stat()
+	if err != nil || info.Size() <= offlineMaxSize {
+		return err
+	}
+	return s.compact()
+}
+
+// compact rewrites the file with a single line per word, dropping the oldest
+// entries until it is back under offlineMaxSize
+func (s *offlineStore) compact() error {
+	if err := s.load(); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if _, err := s.writeTo(&buf); err != nil {
+		return err
+	}
+	if limit := int64(offlineMaxSize); int64(buf.Len()) > limit {
+		s.evict(int64(float64(limit) * pruneLowWater))
+		buf.Reset()
+		if _, err := s.writeTo(&buf); err != nil {
+			return err
+		}
+	}
+
+	return writeFileAtomic(s.path, buf.Bytes())
+}
+
+// evict drops the entries fetched longest ago until the rest take up to
+// target bytes in the file
+func (s *offlineStore) evict(target int64) {
+	keys := make([]string, 0, len(s.entries))
+	sizes := make(map[string]int64, len(s.entries))
+	var total int64
+	for key, rec := range s.entries {
+		line, err := json.Marshal(rec)
+		if err != nil {
+			continue
+		}
+		keys = append(keys, key)
+		sizes[key] = int64(len(line)) + 1
+		total += sizes[key]
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return s.entries[keys[i]].FetchedAt.Before(s.entries[keys[j]].FetchedAt)
+	})
+
+	for _, key := range keys {
+		if total <= target {
+			break
+		}
+		delete(s.entries, key)
+		total -= sizes[key]
+	}
+}
+
+// Search returns the stored words starting with terms followed by the ones
+// containing it, shaped like API results so callers need no special casing
+func (s *offlineStore) Search(terms string) []rae.SearchResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return nil
+	}
+
+	needle := cacheKey(terms)
+	if needle == "" {
+		return nil
+	}
+
+	var prefix, contains []string
+	for key := range s.entries {
+		switch {
+		case strings.HasPrefix(key, needle):
+			prefix = append(prefix, key)
+		case strings.Contains(key, needle):
+			contains = append(contains, key)
+		}
+	}
+	sort.Strings(prefix)
+	sort.Strings(contains)
+
+	keys := append(prefix, contains...)
+	if len(keys) > offlineSearchLimit {
+		keys = keys[:offlineSearchLimit]
+	}
+
+	results := make([]rae.SearchResult, 0, len(keys))
+	for _, key := range keys {
+		entry := s.entries[key].Entry
+		raw, err := json.Marshal(entry)
+		if err != nil {
+			continue
+		}
+
+		var result rae.SearchResult
+		result.Doc.Word = entry.Word
+		result.Doc.Raw = string(raw)
+		result.Hits = 1
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// Import merges the wordRecord lines of r into the store and rewrites the
+// file compacted. It returns how many records were read
+func (s *offlineStore) Import(r io.Reader) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return 0, err
+	}
+
+	imported, err := s.merge(r)
+	if err != nil {
+		return imported, err
+	}
+
+	var buf bytes.Buffer
+	if _, err := s.writeTo(&buf); err != nil {
+		return imported, err
+	}
+
+	return imported, writeFileAtomic(s.path, buf.Bytes())
+}
+
+// Export writes every stored entry to w as JSON lines sorted by word
+func (s *offlineStore) Export(w io.Writer) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return 0, err
+	}
+
+	return s.writeTo(w)
+}
+
+func (s *offlineStore) writeTo(w io.Writer) (int, error) {
+	keys := make([]string, 0, len(s.entries))
+	for key := range s.entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for i, key := range keys {
+		if err := enc.Encode(s.entries[key]); err != nil {
+			return i, err
+		}
+	}
+
+	return len(keys), nil
+}
+
+func (s *offlineStore) Len() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return 0, err
+	}
+	return len(s.entries), nil
+}
+
+// connectivity is implemented by dictionaries that can tell whether the last
+// lookup was served from the local store instead of the API
+type connectivity interface {
+	Offline() bool
+}
+
+func isOffline(cli dictionary) bool {
+	c, ok := cli.(connectivity)
+	return ok && c.Offline()
+}
+
+type offlineMarkKey struct{}
+
+// withOfflineMark returns ctx carrying a flag raised when a lookup made with
+// it is served from the local store. Unlike isOffline, which tells about the
+// last lookup of the client, it only covers the lookups of one caller, so
+// concurrent requests each learn how they were served
+func withOfflineMark(ctx context.Context) (context.Context, *atomic.Bool) {
+	mark := new(atomic.Bool)
+	return context.WithValue(ctx, offlineMarkKey{}, mark), mark
+}
+
+func markOffline(ctx context.Context) {
+	if mark, ok := ctx.Value(offlineMarkKey{}).(*atomic.Bool); ok {
+		mark.Store(true)
+	}
+}
+
+// isNotFound tells apart a word the API does not know, which comes back with
+// the queried word set, from a failure to reach the API at all
+func isNotFound(entry rae.WordEntry, err error) bool {
+	return err != nil && entry.Word != ""
+}
+
+// offlineClient falls back to the local store whenever next cannot be
+// reached, and records every successful lookup in it so the store grows with
+// regular use. A nil next forces offline mode
+type offlineClient struct {
+	next    dictionary
+	store   *offlineStore
+	offline atomic.Bool
+}
+
+func newOfflineClient(next dictionary, store *offlineStore) *offlineClient {
+	c := &offlineClient{next: next, store: store}
+	c.offline.Store(next == nil)
+	return c
+}
+
+// local returns a client serving lookups from the store alone
+func (c *offlineClient) local() *offlineClient {
+	return newOfflineClient(nil, c.store)
+}
+
+func (c *offlineClient) Offline() bool {
+	return c.offline.Load()
+}
+
+func (c *offlineClient) Word(ctx context.Context, word string) (rae.WordEntry, error) {
+	if c.next != nil {
+		lookupCtx, cached := withCacheHitMark(ctx)
+		entry, err := c.next.Word(lookupCtx, word)
+		if err == nil {
+			c.offline.Store(false)
+			// A cached entry was stored when it was fetched
+			if !cached.Load() {
+				_ = c.store.Put(word, entry)
+			}
+			return entry, nil
+		}
+		if ctx.Err() != nil {
+			// Canceled, not offline
+			return entry, err
+		}
+		if isNotFound(entry, err) {
+			c.offline.Store(false)
+			return entry, err
+		}
+		c.offline.Store(true)
+	}
+	markOffline(ctx)
+
+	if entry, ok := c.store.Get(word); ok {
+		return entry, nil
+	}
+	return rae.WordEntry{}, errOfflineMiss
+}
+
+func (c *offlineClient) Search(ctx context.Context, terms string) ([]rae.SearchResult, error) {
+	upstream := errOfflineMiss
+	if c.next != nil {
+		results, err := c.next.Search(ctx, terms)
+		if err == nil {
+			c.offline.Store(false)
+			return results, nil
+		}
+		if ctx.Err() != nil {
+			return results, err
+		}
+		c.offline.Store(true)
+		upstream = err
+	}
+	markOffline(ctx)
+
+	// Nothing in the store is a failure, not an empty search, so that it is
+	// reported the same as a word missing offline
+	if results := c.store.Search(terms); len(results) > 0 {
+		return results, nil
+	}
+	return nil, upstream
+}
+
+func runOfflineCommand(store *offlineStore, cache *diskCache, params []string) int {
+	if len(params) == 0 {
+		params = []string{"stats"}
+	}
+
+	switch params[0] {
+	case "stats":
+		count, err := store.Len()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+			return exitError
+		}
+		fmt.Printf(tr("Fichero:  %s\n"), store.path)
+		fmt.Printf(tr("Palabras: %d\n"), count)
+		return exitFound
+
+	case "import":
+		var r io.Reader
+		source := tr("la caché")
+		switch {
+		case len(params) > 1 && params[1] == "-":
+			r = os.Stdin
+			source = tr("la entrada estándar")
+		case len(params) > 1:
+			f, err := os.Open(params[1])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+				return exitError
+			}
+			defer f.Close()
+			r = f
+			source = params[1]
+		default:
+			var buf bytes.Buffer
+			if _, err := cache.Export(&buf); err != nil {
+				fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+				return exitError
+			}
+			r = &buf
+		}
+
+		imported, err := store.Import(r)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+			return exitError
+		}
+		fmt.Printf(tr("Importadas %d palabras desde %s\n"), imported, source)
+		return exitFound
+
+	case "export":
+		w := io.Writer(os.Stdout)
+		if len(params) > 1 {
+			f, err := os.Create(params[1])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+				return exitError
+			}
+			defer f.Close()
+			w = f
+		}
+		exported, err := store.Export(w)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+			return exitError
+		}
+		fmt.Fprintf(os.Stderr, tr("Exportadas %d palabras\n"), exported)
+		return exitFound
+
+	default:
+		return usageFailure("offline", "subcomando offline desconocido: %q", params[0])
+	}
+}
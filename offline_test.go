@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	rae "github.com/rae-api-com/go-rae"
+)
+
+func fileLines(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func TestOfflineStoreCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "offline.jsonl")
+
+	// Every process appends its lookups, so the file holds older versions of
+	// the words looked up again
+	for _, meaning := range []string{"primera", "segunda", "tercera"} {
+		store := newOfflineStore(path)
+		for _, word := range []string{"casa", "perro"} {
+			entry := rae.WordEntry{Word: word, Meanings: []rae.Meaning{{Definitions: []rae.Definition{{Raw: meaning}}}}}
+			if err := store.Put(word, entry); err != nil {
+				t.Fatal(err)
+			}
+			// Again in the same process, which is not appended
+			if err := store.Put(word, entry); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if lines := fileLines(t, path); len(lines) != 6 {
+		t.Fatalf("appended %d lines, want 6", len(lines))
+	}
+
+	store := newOfflineStore(path)
+	if err := store.compact(); err != nil {
+		t.Fatal(err)
+	}
+	if lines := fileLines(t, path); len(lines) != 2 {
+		t.Fatalf("compacted to %d lines, want 2", len(lines))
+	}
+
+	reloaded := newOfflineStore(path)
+	for _, word := range []string{"casa", "perro"} {
+		entry, ok := reloaded.Get(word)
+		if !ok {
+			t.Fatalf("%s missing after compaction", word)
+		}
+		if raw := entry.Meanings[0].Definitions[0].Raw; raw != "tercera" {
+			t.Errorf("%s kept %q, want the latest entry", word, raw)
+		}
+	}
+}
+
+func TestOfflineStoreEvict(t *testing.T) {
+	store := newOfflineStore(filepath.Join(t.TempDir(), "offline.jsonl"))
+	if err := store.load(); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	words := []string{"sol", "mar", "pan", "luz"}
+	for i, word := range words {
+		store.entries[word] = wordRecord{
+			Key:       word,
+			FetchedAt: now.Add(time.Duration(i) * time.Hour),
+			Entry:     rae.WordEntry{Word: word},
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := store.writeTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	// Room for half of the entries leaves the two fetched last
+	store.evict(int64(buf.Len()) / 2)
+
+	var kept []string
+	for word := range store.entries {
+		kept = append(kept, word)
+	}
+	slices.Sort(kept)
+	if want := []string{"luz", "pan"}; !slices.Equal(kept, want) {
+		t.Errorf("kept %q, want %q", kept, want)
+	}
+}
+
+// fakeDictionary knows every word and counts the lookups reaching it
+type fakeDictionary struct {
+	lookups int
+}
+
+func (d *fakeDictionary) Word(_ context.Context, word string) (rae.WordEntry, error) {
+	d.lookups++
+	return rae.WordEntry{Word: word}, nil
+}
+
+func (d *fakeDictionary) Search(context.Context, string) ([]rae.SearchResult, error) {
+	return nil, nil
+}
+
+func TestOfflineClientSkipsCachedEntries(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "offline.jsonl")
+	api := new(fakeDictionary)
+	cache := newDiskCache(cacheConfig{dir: filepath.Join(dir, "cache")})
+
+	// Each run is a new process looking the word up once
+	for range 3 {
+		cli := newOfflineClient(newCachedClient(api, cache), newOfflineStore(path))
+		if _, err := cli.Word(context.Background(), "casa"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if api.lookups != 1 {
+		t.Fatalf("API looked up %d times, want 1", api.lookups)
+	}
+	if lines := fileLines(t, path); len(lines) != 1 {
+		t.Errorf("appended %d lines, want 1", len(lines))
+	}
+
+	// The same entry fetched again once the store is loaded is not appended
+	store := newOfflineStore(path)
+	if _, ok := store.Get("casa"); !ok {
+		t.Fatal("casa missing from the store")
+	}
+	if err := store.Put("casa", rae.WordEntry{Word: "casa"}); err != nil {
+		t.Fatal(err)
+	}
+	if lines := fileLines(t, path); len(lines) != 1 {
+		t.Errorf("appended %d lines after an unchanged update, want 1", len(lines))
+	}
+}
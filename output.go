@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	rae "github.com/rae-api-com/go-rae"
+)
+
+type outputFormat string
+
+const (
+	formatText outputFormat = "text"
+	formatJSON outputFormat = "json"
+	// formatCSV is only supported by the commands producing tabular data
+	formatCSV outputFormat = "csv"
+	// formatJSONL and formatMarkdown are only supported by batch
+	formatJSONL    outputFormat = "jsonl"
+	formatMarkdown outputFormat = "markdown"
+	// formatAnki and formatHTML are only supported by export
+	formatAnki outputFormat = "anki"
+	formatHTML outputFormat = "html"
+)
+
+func parseOutputFormat(s string) (outputFormat, error) {
+	switch f := outputFormat(strings.ToLower(strings.TrimSpace(s))); f {
+	case formatText, formatJSON, formatCSV, formatJSONL, formatMarkdown, formatAnki, formatHTML:
+		return f, nil
+	default:
+		return "", fmt.Errorf(tr("formato de salida desconocido: %q (usa text, json, jsonl, csv, markdown, anki o html)"), s)
+	}
+}
+
+// Exit codes of the non-TUI lookup, so scripts can branch without parsing output
+const (
+	exitFound     = 0
+	exitError     = 1
+	exitSuggested = 2
+	exitNotFound  = 3
+)
+
+// jsonSchemaVersion is bumped whenever the shape of jsonLookup changes incompatibly
+const jsonSchemaVersion = 1
+
+type lookupStatus string
+
+const (
+	statusFound     lookupStatus = "found"
+	statusSuggested lookupStatus = "suggested"
+	statusNotFound  lookupStatus = "not_found"
+	statusError     lookupStatus = "error"
+)
+
+// jsonLookup is the document written by `--format json`
+type jsonLookup struct {
+	SchemaVersion int             `json:"schema_version"`
+	Query         string          `json:"query"`
+	Status        lookupStatus    `json:"status"`
+	Offline       bool            `json:"offline,omitempty"`
+	Entry         *rae.WordEntry  `json:"entry,omitempty"`
+	Lemma         *jsonLemma      `json:"lemma,omitempty"`
+	Suggestions   []string        `json:"suggestions,omitempty"`
+	Results       []jsonSearchHit `json:"results,omitempty"`
+	Error         string          `json:"error,omitempty"`
+
+	// err is the error of the word lookup, if it failed
+	err error
+}
+
+// jsonLemma is set when the query is an inflected form of Entry
+type jsonLemma struct {
+	Form     string   `json:"form"`
+	Lemma    string   `json:"lemma"`
+	Analyses []string `json:"analyses"`
+}
+
+// jsonSearchHit is a single fuzzy search result
+type jsonSearchHit struct {
+	Word  string         `json:"word"`
+	Hits  int            `json:"hits"`
+	Entry *rae.WordEntry `json:"entry,omitempty"`
+}
+
+func (s lookupStatus) exitCode() int {
+	switch s {
+	case statusFound:
+		return exitFound
+	case statusSuggested:
+		return exitSuggested
+	case statusNotFound:
+		return exitNotFound
+	default:
+		return exitError
+	}
+}
+
+// lookupJSON resolves word the same way renderNoTUI does, but without any
+// interaction: suggestions and fuzzy search hits are reported instead of prompted
+func lookupJSON(ctx context.Context, cli dictionary, word string) jsonLookup {
+	doc := jsonLookup{
+		SchemaVersion: jsonSchemaVersion,
+		Query:         word,
+	}
+
+	res, err := cli.Word(ctx, word)
+	if err == nil {
+		doc.Status = statusFound
+		doc.Entry = &res
+		return doc
+	}
+	doc.err = err
+
+	if shouldLemmatize(res, err) {
+		if match, ok := lemmatize(ctx, lemmaSource(cli, err), word); ok {
+			doc.Status = statusFound
+			doc.Entry = &match.Entry
+			doc.Lemma = &jsonLemma{Form: match.Form, Lemma: match.Lemma, Analyses: match.Analyses}
+			return doc
+		}
+	}
+
+	if len(res.Suggestions) > 0 {
+		doc.Status = statusSuggested
+		doc.Suggestions = res.Suggestions
+		return doc
+	}
+
+	searchResults, searchErr := cli.Search(ctx, word)
+	if searchErr != nil {
+		// The word lookup already failed; only report an error when the API
+		// could not be reached at all, otherwise it is simply not found
+		if res.Word == "" {
+			doc.Status = statusError
+			doc.Error = err.Error()
+			return doc
+		}
+		doc.Status = statusNotFound
+		return doc
+	}
+
+	if len(searchResults) == 0 {
+		doc.Status = statusNotFound
+		return doc
+	}
+
+	doc.Status = statusSuggested
+	doc.Results = searchHits(searchResults)
+	return doc
+}
+
+func searchHits(results []rae.SearchResult) []jsonSearchHit {
+	hits := make([]jsonSearchHit, 0, len(results))
+	for _, result := range results {
+		hit := jsonSearchHit{
+			Word: result.Doc.Word,
+			Hits: result.Hits,
+		}
+		if entry, err := result.WordEntry(); err == nil {
+			hit.Entry = entry
+		}
+		hits = append(hits, hit)
+	}
+	return hits
+}
+
+// renderJSON writes the lookup of word as an indented JSON document and
+// returns the exit code matching its status
+func renderJSON(ctx context.Context, cli dictionary, word string, w io.Writer) int {
+	doc := lookupJSON(ctx, cli, word)
+	doc.Offline = isOffline(cli)
+	if err := writeJSON(w, doc); err != nil {
+		return exitError
+	}
+
+	return doc.Status.exitCode()
+}
+
+// writeJSON writes v as an indented JSON document
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
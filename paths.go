@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// dataDir returns the directory where rae-tui keeps user data that must not be
+// evicted like the cache: $XDG_DATA_HOME/rae-tui or ~/.local/share/rae-tui
+func dataDir() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "rae-tui")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "rae-tui")
+	}
+	return filepath.Join(home, ".local", "share", "rae-tui")
+}
+
+// writeFileAtomic writes data to path through a temporary file in the same
+// directory, so readers never observe a partially written file
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
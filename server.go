@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// serverShutdownTimeout is how long requests in flight may take to finish
+	// once the server is asked to stop
+	serverShutdownTimeout = 10 * time.Second
+	serverReadTimeout     = 10 * time.Second
+)
+
+// server exposes the lookups as a JSON API, going through the same cache and
+// offline layers as the CLI
+type server struct {
+	cli     dictionary
+	log     *log.Logger
+	started time.Time
+}
+
+// jsonError is the body of the responses to invalid requests
+type jsonError struct {
+	Error string `json:"error"`
+}
+
+// jsonHealth is the body of /health
+type jsonHealth struct {
+	Status  string `json:"status"`
+	Version string `json:"version"`
+	Uptime  string `json:"uptime"`
+	Offline bool   `json:"offline"`
+}
+
+func newServer(cli dictionary, logger *log.Logger) http.Handler {
+	s := &server{cli: cli, log: logger, started: time.Now()}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", s.handleHealth)
+	mux.HandleFunc("GET /word/{word}", s.handleWord)
+	mux.HandleFunc("GET /search", s.handleSearch)
+	mux.HandleFunc("GET /conjugate/{verb}", s.handleConjugate)
+	mux.HandleFunc("GET /", func(w http.ResponseWriter, r *http.Request) {
+		writeResponse(w, http.StatusNotFound, jsonError{Error: tr("ruta desconocida: ") + r.URL.Path})
+	})
+
+	return s.logRequests(mux)
+}
+
+// statusHTTP maps the status of a lookup to the status code of its response
+func statusHTTP(status lookupStatus) int {
+	switch status {
+	case statusFound:
+		return http.StatusOK
+	case statusSuggested, statusNotFound:
+		return http.StatusNotFound
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+func writeResponse(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	_ = writeJSON(w, v)
+}
+
+func (s *server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	writeResponse(w, http.StatusOK, jsonHealth{
+		Status:  "ok",
+		Version: version,
+		Uptime:  time.Since(s.started).Round(time.Second).String(),
+		Offline: isOffline(s.cli),
+	})
+}
+
+func (s *server) handleWord(w http.ResponseWriter, r *http.Request) {
+	word := strings.TrimSpace(r.PathValue("word"))
+	if word == "" {
+		writeResponse(w, http.StatusBadRequest, jsonError{Error: tr("falta la palabra")})
+		return
+	}
+
+	ctx, offline := withOfflineMark(r.Context())
+	doc := lookupJSON(ctx, s.cli, word)
+	doc.Offline = offline.Load()
+	writeResponse(w, statusHTTP(doc.Status), doc)
+}
+
+func (s *server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	terms := strings.TrimSpace(query.Get("q"))
+	if terms == "" {
+		writeResponse(w, http.StatusBadRequest, jsonError{Error: tr("falta el parámetro q")})
+		return
+	}
+
+	limit := 0
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeResponse(w, http.StatusBadRequest, jsonError{Error: tr("limit inválido: ") + v})
+			return
+		}
+		limit = n
+	}
+
+	ctx, offline := withOfflineMark(r.Context())
+	doc := jsonLookup{SchemaVersion: jsonSchemaVersion, Query: terms}
+	results, err := s.cli.Search(ctx, terms)
+	switch {
+	case err != nil:
+		doc.Status = statusError
+		doc.Error = err.Error()
+	case len(results) == 0:
+		doc.Status = statusNotFound
+	default:
+		if limit > 0 && len(results) > limit {
+			results = results[:limit]
+		}
+		doc.Status = statusFound
+		doc.Results = searchHits(results)
+	}
+	doc.Offline = offline.Load()
+	writeResponse(w, statusHTTP(doc.Status), doc)
+}
+
+// handleConjugate accepts the filters of `rae-tui conjugate` as the repeatable
+// or comma separated mood, tense and person parameters
+func (s *server) handleConjugate(w http.ResponseWriter, r *http.Request) {
+	verb := strings.TrimSpace(r.PathValue("verb"))
+	query := r.URL.Query()
+
+	filter, err := newConjugationFilter(query["mood"], query["tense"], query["person"])
+	if err != nil {
+		writeResponse(w, http.StatusBadRequest, jsonError{Error: err.Error()})
+		return
+	}
+
+	ctx, offline := withOfflineMark(r.Context())
+	doc := conjugationJSON(ctx, s.cli, verb, filter)
+	doc.Offline = offline.Load()
+	writeResponse(w, statusHTTP(doc.Status), doc)
+}
+
+// statusRecorder remembers the status code written through it
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// logRequests logs every request with its status and duration
+func (s *server) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		s.log.Printf("%s %s %d %s %s", r.Method, r.URL.RequestURI(), rec.status,
+			time.Since(start).Round(time.Millisecond), r.RemoteAddr)
+	})
+}
+
+// serve runs the API on addr until ctx is done, then waits for the requests
+// in flight before returning
+func serve(ctx context.Context, addr string, handler http.Handler, logger *log.Logger) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: serverReadTimeout,
+		ErrorLog:          logger,
+	}
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+	logger.Printf("Escuchando en http://%s", ln.Addr())
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.Printf("Deteniendo el servidor...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SM-2 parameters: the ease of new cards, the lowest ease a card can reach and
+// the grades, from 0 to 5, counted as remembered
+const (
+	studyInitialEase = 2.5
+	studyMinEase     = 1.3
+	studyPassGrade   = 3
+	studyMaxGrade    = 5
+	// studyMatureDays is the interval from which a card counts as mature
+	studyMatureDays = 21
+)
+
+func defaultStudyPath() string {
+	return filepath.Join(dataDir(), "study.json")
+}
+
+// studyCard is the review schedule of a word
+type studyCard struct {
+	Word        string    `json:"word"`
+	Ease        float64   `json:"ease"`
+	Interval    int       `json:"interval_days"`
+	Repetitions int       `json:"repetitions"`
+	Due         time.Time `json:"due"`
+	Reviews     int       `json:"reviews"`
+	Lapses      int       `json:"lapses"`
+	LastGrade   int       `json:"last_grade"`
+	LastReview  time.Time `json:"last_review"`
+}
+
+func (c studyCard) isNew() bool {
+	return c.Reviews == 0
+}
+
+// schedule returns the card after a review graded grade at now, following
+// SM-2: a failed card starts over tomorrow, a remembered one is seen again
+// after 1 day, 6 days and then its last interval times its ease, which grows
+// or shrinks with the grade
+func (c studyCard) schedule(grade int, now time.Time) studyCard {
+	q := float64(grade)
+	c.Ease = math.Max(studyMinEase, c.Ease+0.1-(5-q)*(0.08+(5-q)*0.02))
+
+	if grade < studyPassGrade {
+		c.Repetitions = 0
+		c.Interval = 1
+		if c.Reviews > 0 {
+			c.Lapses++
+		}
+	} else {
+		switch c.Repetitions {
+		case 0:
+			c.Interval = 1
+		case 1:
+			c.Interval = 6
+		default:
+			c.Interval = int(math.Round(float64(c.Interval) * c.Ease))
+		}
+		c.Repetitions++
+	}
+
+	c.Reviews++
+	c.LastGrade = grade
+	c.LastReview = now
+	c.Due = now.AddDate(0, 0, c.Interval)
+	return c
+}
+
+// studyDeck is the persisted set of flashcards, keyed by cacheKey
+type studyDeck struct {
+	path string
+
+	mu    sync.Mutex
+	cards map[string]studyCard
+}
+
+// loadStudyDeck reads the deck at path. A missing file is an empty deck
+func loadStudyDeck(path string) (*studyDeck, error) {
+	d := &studyDeck{path: path, cards: make(map[string]studyCard)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return d, nil
+	}
+	if err != nil {
+		return d, err
+	}
+
+	var cards []studyCard
+	if err := json.Unmarshal(data, &cards); err != nil {
+		return d, err
+	}
+	for _, card := range cards {
+		d.cards[cacheKey(card.Word)] = card
+	}
+	return d, nil
+}
+
+func (d *studyDeck) save() error {
+	cards := make([]studyCard, 0, len(d.cards))
+	for _, card := range d.cards {
+		cards = append(cards, card)
+	}
+	sort.Slice(cards, func(i, j int) bool { return cards[i].Word < cards[j].Word })
+
+	data, err := json.MarshalIndent(cards, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(d.path, data)
+}
+
+// Sync adds a new card, due right away, for every word not in the deck yet
+// and returns how many were added. Cards are kept when their word leaves the
+// favorites or the history
+func (d *studyDeck) Sync(words []string, now time.Time) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	added := 0
+	for _, word := range words {
+		key := cacheKey(word)
+		if _, ok := d.cards[key]; ok || key == "" {
+			continue
+		}
+		d.cards[key] = studyCard{Word: word, Ease: studyInitialEase, Due: now}
+		added++
+	}
+	if added == 0 {
+		return 0, nil
+	}
+	return added, d.save()
+}
+
+// Due returns the cards to review at now, the most overdue first, followed
+// by up to newLimit new cards
+func (d *studyDeck) Due(now time.Time, newLimit int) []studyCard {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var due, fresh []studyCard
+	for _, card := range d.cards {
+		switch {
+		case card.isNew():
+			fresh = append(fresh, card)
+		case !card.Due.After(now):
+			due = append(due, card)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool { return due[i].Due.Before(due[j].Due) })
+	sort.Slice(fresh, func(i, j int) bool { return fresh[i].Word < fresh[j].Word })
+	if len(fresh) > newLimit {
+		fresh = fresh[:newLimit]
+	}
+	return append(due, fresh...)
+}
+
+// Review records a review of word graded from 0 (forgotten) to 5 (perfect
+// recall) and schedules the next one
+func (d *studyDeck) Review(word string, grade int, now time.Time) (studyCard, error) {
+	if grade < 0 || grade > studyMaxGrade {
+		return studyCard{}, fmt.Errorf(tr("nota inválida %d, debe estar entre 0 y %d"), grade, studyMaxGrade)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := cacheKey(word)
+	card, ok := d.cards[key]
+	if !ok {
+		card = studyCard{Word: word, Ease: studyInitialEase}
+	}
+	card = card.schedule(grade, now)
+	d.cards[key] = card
+	return card, d.save()
+}
+
+// studyStats summarizes the progress of a deck
+type studyStats struct {
+	Cards    int       `json:"cards"`
+	New      int       `json:"new"`
+	DueToday int       `json:"due_today"`
+	Learning int       `json:"learning"`
+	Mature   int       `json:"mature"`
+	Reviews  int       `json:"reviews"`
+	Lapses   int       `json:"lapses"`
+	Ease     float64   `json:"average_ease"`
+	NextDue  time.Time `json:"next_due,omitzero"`
+}
+
+// Stats summarizes the deck, counting as due today the reviews due before
+// the end of the day of now
+func (d *studyDeck) Stats(now time.Time) studyStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	endOfDay := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+
+	var stats studyStats
+	var ease float64
+	for _, card := range d.cards {
+		stats.Cards++
+		stats.Reviews += card.Reviews
+		stats.Lapses += card.Lapses
+		if card.isNew() {
+			stats.New++
+			continue
+		}
+
+		ease += card.Ease
+		if card.Interval >= studyMatureDays {
+			stats.Mature++
+		} else {
+			stats.Learning++
+		}
+		if card.Due.Before(endOfDay) {
+			stats.DueToday++
+		}
+		if stats.NextDue.IsZero() || card.Due.Before(stats.NextDue) {
+			stats.NextDue = card.Due
+		}
+	}
+	if reviewed := stats.Cards - stats.New; reviewed > 0 {
+		stats.Ease = math.Round(ease/float64(reviewed)*100) / 100
+	}
+	return stats
+}
+
+// runStudyStats prints the progress of the deck
+func runStudyStats(deck *studyDeck, format outputFormat) int {
+	stats := deck.Stats(time.Now())
+
+	if format == formatJSON {
+		if err := writeJSON(os.Stdout, stats); err != nil {
+			fmt.Fprintf(os.Stderr, "rae-tui: %v\n", err)
+			return exitError
+		}
+		return exitFound
+	}
+
+	fmt.Printf(tr("Tarjetas:          %d\n"), stats.Cards)
+	fmt.Printf(tr("Nuevas:            %d\n"), stats.New)
+	fmt.Printf(tr("Para hoy:          %d\n"), stats.DueToday)
+	fmt.Printf(tr("En aprendizaje:    %d\n"), stats.Learning)
+	fmt.Printf(tr("Maduras:           %d\n"), stats.Mature)
+	fmt.Printf(tr("Repasos:           %d (%d fallos)\n"), stats.Reviews, stats.Lapses)
+	if stats.Ease > 0 {
+		fmt.Printf(tr("Facilidad media:   %.2f\n"), stats.Ease)
+	}
+	if !stats.NextDue.IsZero() {
+		fmt.Printf(tr("Próximo repaso:    %s\n"), stats.NextDue.Local().Format(time.DateTime))
+	}
+	return exitFound
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"math"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestStudyCardSchedule(t *testing.T) {
+	now := time.Date(2024, time.March, 9, 8, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		grade       int
+		ease        float64
+		interval    int
+		repetitions int
+		lapses      int
+	}{
+		{grade: 5, ease: 2.6, interval: 1, repetitions: 1},
+		{grade: 4, ease: 2.6, interval: 6, repetitions: 2},
+		{grade: 3, ease: 2.46, interval: 15, repetitions: 3},
+		{grade: 1, ease: 1.92, interval: 1, repetitions: 0, lapses: 1},
+		{grade: 5, ease: 2.02, interval: 1, repetitions: 1, lapses: 1},
+		{grade: 0, ease: 1.3, interval: 1, repetitions: 0, lapses: 2},
+		{grade: 0, ease: 1.3, interval: 1, repetitions: 0, lapses: 3},
+	}
+
+	card := studyCard{Word: "casa", Ease: studyInitialEase}
+	for i, step := range steps {
+		card = card.schedule(step.grade, now)
+		if math.Abs(card.Ease-step.ease) > 1e-9 {
+			t.Errorf("review %d: ease = %v, want %v", i+1, card.Ease, step.ease)
+		}
+		if card.Interval != step.interval || card.Repetitions != step.repetitions || card.Lapses != step.lapses {
+			t.Errorf("review %d: interval = %d, repetitions = %d, lapses = %d, want %d, %d, %d",
+				i+1, card.Interval, card.Repetitions, card.Lapses, step.interval, step.repetitions, step.lapses)
+		}
+		if want := now.AddDate(0, 0, step.interval); !card.Due.Equal(want) {
+			t.Errorf("review %d: due = %v, want %v", i+1, card.Due, want)
+		}
+		if card.Reviews != i+1 || card.LastGrade != step.grade {
+			t.Errorf("review %d: reviews = %d, last grade = %d", i+1, card.Reviews, card.LastGrade)
+		}
+	}
+
+	// A new card forgotten on its first review is not a lapse
+	if first := (studyCard{Ease: studyInitialEase}).schedule(0, now); first.Lapses != 0 {
+		t.Errorf("lapses after failing a new card = %d, want 0", first.Lapses)
+	}
+}
+
+func TestStudyDeckDue(t *testing.T) {
+	deck, err := loadStudyDeck(filepath.Join(t.TempDir(), "study.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2024, time.March, 9, 8, 0, 0, 0, time.UTC)
+	if _, err := deck.Sync([]string{"casa", "perro", "gato", "árbol", "luz"}, now.AddDate(0, 0, -10)); err != nil {
+		t.Fatal(err)
+	}
+	// perro is due yesterday, gato today, luz tomorrow
+	for _, review := range []struct {
+		word string
+		at   time.Time
+	}{
+		{"perro", now.AddDate(0, 0, -2)},
+		{"gato", now.AddDate(0, 0, -1)},
+		{"luz", now},
+	} {
+		if _, err := deck.Review(review.word, 4, review.at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var words []string
+	for _, card := range deck.Due(now, 1) {
+		words = append(words, card.Word)
+	}
+	if want := []string{"perro", "gato", "casa"}; !slices.Equal(words, want) {
+		t.Errorf("due = %q, want %q", words, want)
+	}
+
+	if _, err := deck.Review("casa", studyMaxGrade+1, now); err == nil {
+		t.Error("grade out of range: no error")
+	}
+}
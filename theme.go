@@ -0,0 +1,281 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"golang.org/x/term"
+)
+
+// Built-in themes
+const (
+	themeDark         = "dark"
+	themeLight        = "light"
+	themeHighContrast = "high-contrast"
+	themeMonochrome   = "monochrome"
+)
+
+// colorDefault names the color of the terminal in themes
+const colorDefault = "default"
+
+// themeColors are the colors of a theme, shared by the TUI and the plain
+// output, as tcell color names, #rrggbb or default. A header, footer or
+// selection whose colors are both default is shown in reverse video
+type themeColors struct {
+	Background         string `toml:"background"`
+	Text               string `toml:"text"`
+	HeaderBackground   string `toml:"header_background"`
+	HeaderText         string `toml:"header_text"`
+	FooterBackground   string `toml:"footer_background"`
+	FooterText         string `toml:"footer_text"`
+	SelectedBackground string `toml:"selected_background"`
+	SelectedText       string `toml:"selected_text"`
+	// Title colors headwords, senses and moods
+	Title string `toml:"title"`
+	// Accent colors labels, numbers and the keys of the help
+	Accent string `toml:"accent"`
+	// Info colors synonyms, tags and the persons of conjugations
+	Info string `toml:"info"`
+	// Muted colors hints and secondary text
+	Muted   string `toml:"muted"`
+	Error   string `toml:"error"`
+	Success string `toml:"success"`
+}
+
+// themeConfig is a user-defined theme of the [themes] table
+type themeConfig struct {
+	// Base is the theme the colors left out come from, dark by default
+	Base string `toml:"base"`
+	themeColors
+}
+
+var themes = map[string]themeColors{
+	themeDark: {
+		Background:         "black",
+		Text:               "white",
+		HeaderBackground:   "green",
+		HeaderText:         "white",
+		FooterBackground:   "darkcyan",
+		FooterText:         "white",
+		SelectedBackground: "darkblue",
+		SelectedText:       "yellow",
+		Title:              "green",
+		Accent:             "yellow",
+		Info:               "teal",
+		Muted:              "gray",
+		Error:              "red",
+		Success:            "green",
+	},
+	themeLight: {
+		Background:         "white",
+		Text:               "black",
+		HeaderBackground:   "green",
+		HeaderText:         "white",
+		FooterBackground:   "teal",
+		FooterText:         "white",
+		SelectedBackground: "lightblue",
+		SelectedText:       "black",
+		Title:              "green",
+		Accent:             "olive",
+		Info:               "teal",
+		Muted:              "gray",
+		Error:              "maroon",
+		Success:            "green",
+	},
+	themeHighContrast: {
+		Background:         "black",
+		Text:               "white",
+		HeaderBackground:   "white",
+		HeaderText:         "black",
+		FooterBackground:   "yellow",
+		FooterText:         "black",
+		SelectedBackground: "yellow",
+		SelectedText:       "black",
+		Title:              "lime",
+		Accent:             "yellow",
+		Info:               "aqua",
+		Muted:              "silver",
+		Error:              "red",
+		Success:            "lime",
+	},
+	themeMonochrome: {
+		Background:         colorDefault,
+		Text:               colorDefault,
+		HeaderBackground:   colorDefault,
+		HeaderText:         colorDefault,
+		FooterBackground:   colorDefault,
+		FooterText:         colorDefault,
+		SelectedBackground: colorDefault,
+		SelectedText:       colorDefault,
+		Title:              colorDefault,
+		Accent:             colorDefault,
+		Info:               colorDefault,
+		Muted:              colorDefault,
+		Error:              colorDefault,
+		Success:            colorDefault,
+	},
+}
+
+// themeNames returns the names of the built-in themes, sorted
+func themeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// over returns colors with the ones set in overrides replacing them
+func (colors themeColors) over(overrides themeColors) themeColors {
+	v, o := reflect.ValueOf(&colors).Elem(), reflect.ValueOf(overrides)
+	for i := 0; i < v.NumField(); i++ {
+		if color := o.Field(i).String(); color != "" {
+			v.Field(i).SetString(color)
+		}
+	}
+	return colors
+}
+
+// resolveTheme returns the colors of the built-in or user-defined theme name,
+// following the bases of the user-defined ones
+func resolveTheme(name string, custom map[string]themeConfig) (themeColors, error) {
+	var chain []themeColors
+	seen := make(map[string]bool)
+	for {
+		if colors, ok := themes[name]; ok {
+			for i := len(chain) - 1; i >= 0; i-- {
+				colors = colors.over(chain[i])
+			}
+			return colors, nil
+		}
+		user, ok := custom[name]
+		if !ok {
+			return themeColors{}, fmt.Errorf(tr("tema desconocido %q, usa %s o uno de [themes]"), name, strings.Join(themeNames(), ", "))
+		}
+		if seen[name] {
+			return themeColors{}, fmt.Errorf(tr("el tema %q se basa en sí mismo"), name)
+		}
+		seen[name] = true
+		chain = append(chain, user.themeColors)
+		name = cmp.Or(user.Base, themeDark)
+	}
+}
+
+// themeColor returns the tcell color of name
+func themeColor(name string) tcell.Color {
+	if name == colorDefault {
+		return tcell.ColorDefault
+	}
+	return tcell.GetColor(strings.ToLower(name))
+}
+
+// themeStyle returns the style of text over background, reversed when both
+// are the colors of the terminal so that it still stands out
+func themeStyle(text, background string) tcell.Style {
+	if text == colorDefault && background == colorDefault {
+		return tcell.StyleDefault.Reverse(true)
+	}
+	return tcell.StyleDefault.Foreground(themeColor(text)).Background(themeColor(background))
+}
+
+// ANSI escapes of the plain output, set by setTheme
+var (
+	Reset   = "\033[0m"
+	Bold    = "\033[1m"
+	Title   = ansiColor(themes[themeDark].Title)
+	Accent  = ansiColor(themes[themeDark].Accent)
+	Info    = ansiColor(themes[themeDark].Info)
+	Muted   = ansiColor(themes[themeDark].Muted)
+	Error   = ansiColor(themes[themeDark].Error)
+	Success = ansiColor(themes[themeDark].Success)
+)
+
+// activeTheme is the theme of the interface, set once at startup
+var activeTheme = themes[themeDark]
+
+// setTheme picks the colors of the interface. NO_COLOR turns colors off, and
+// the plain output has no escapes at all unless the standard output is a
+// terminal
+func setTheme(colors themeColors) {
+	if os.Getenv("NO_COLOR") != "" {
+		colors = themes[themeMonochrome]
+	}
+	activeTheme = colors
+
+	Title, Accent, Info = ansiColor(colors.Title), ansiColor(colors.Accent), ansiColor(colors.Info)
+	Muted, Error, Success = ansiColor(colors.Muted), ansiColor(colors.Error), ansiColor(colors.Success)
+	Reset, Bold = "\033[0m", "\033[1m"
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		Reset, Bold, Title, Accent, Info, Muted, Error, Success = "", "", "", "", "", "", "", ""
+	}
+}
+
+// ansiColor returns the escape setting the foreground to the color name, or
+// nothing for the color of the terminal
+func ansiColor(name string) string {
+	color := themeColor(name)
+	switch {
+	case color == tcell.ColorDefault:
+		return ""
+	case color.IsRGB():
+		r, g, b := color.RGB()
+		return fmt.Sprintf("\033[38;2;%d;%d;%dm", r, g, b)
+	}
+
+	index := int(color - tcell.ColorValid)
+	switch {
+	case index < 8:
+		return fmt.Sprintf("\033[%dm", 30+index)
+	case index < 16:
+		return fmt.Sprintf("\033[%dm", 90+index-8)
+	default:
+		return fmt.Sprintf("\033[38;5;%dm", index)
+	}
+}
+
+// applyTUI makes the widgets created from then on use colors, and the
+// [title], [accent], [info], [muted], [error] and [success] color tags of
+// their texts name them
+func (colors themeColors) applyTUI() {
+	background, text := themeColor(colors.Background), themeColor(colors.Text)
+	tview.Styles = tview.Theme{
+		PrimitiveBackgroundColor:    background,
+		ContrastBackgroundColor:     themeColor(colors.SelectedBackground),
+		MoreContrastBackgroundColor: themeColor(colors.FooterBackground),
+		BorderColor:                 text,
+		TitleColor:                  text,
+		GraphicsColor:               themeColor(colors.Muted),
+		PrimaryTextColor:            text,
+		SecondaryTextColor:          themeColor(colors.Accent),
+		TertiaryTextColor:           themeColor(colors.Title),
+		InverseTextColor:            themeColor(colors.SelectedText),
+		ContrastSecondaryTextColor:  themeColor(colors.Info),
+	}
+
+	for tag, name := range map[string]string{
+		"title":   colors.Title,
+		"accent":  colors.Accent,
+		"info":    colors.Info,
+		"muted":   colors.Muted,
+		"error":   colors.Error,
+		"success": colors.Success,
+	} {
+		tcell.ColorNames[tag] = themeColor(name)
+	}
+}
+
+// badgeTag returns the color tag of a badge of the header, the background
+// color over the accent, reversed when the accent is the color of the terminal
+func (colors themeColors) badgeTag() string {
+	if colors.Accent == colorDefault {
+		return "[::rb]"
+	}
+	return fmt.Sprintf("[%s:%s:b]", colors.Background, colors.Accent)
+}
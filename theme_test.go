@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveTheme(t *testing.T) {
+	custom := map[string]themeConfig{
+		"solarized": {Base: themeLight, themeColors: themeColors{Accent: "#b58900"}},
+		"night":     {themeColors: themeColors{Title: "aqua"}},
+		"solarnight": {
+			Base:        "solarized",
+			themeColors: themeColors{Background: "black", Text: "white"},
+		},
+		"loop":  {Base: "loop2"},
+		"loop2": {Base: "loop"},
+	}
+
+	tests := []struct {
+		name string
+		// want is the expected theme, given as the built-in it starts from
+		// and the colors changed over it
+		base    string
+		changes themeColors
+		err     string
+	}{
+		{name: themeDark, base: themeDark},
+		{name: themeMonochrome, base: themeMonochrome},
+		{name: "solarized", base: themeLight, changes: themeColors{Accent: "#b58900"}},
+		{name: "night", base: themeDark, changes: themeColors{Title: "aqua"}},
+		{
+			name: "solarnight", base: themeLight,
+			changes: themeColors{Background: "black", Text: "white", Accent: "#b58900"},
+		},
+		{name: "sepia", err: "tema desconocido"},
+		{name: "loop", err: "se basa en sí mismo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			colors, err := resolveTheme(tt.name, custom)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := themes[tt.base].over(tt.changes); colors != want {
+				t.Errorf("colors = %+v, want %+v", colors, want)
+			}
+		})
+	}
+}
+
+func TestAnsiColor(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: colorDefault, want: ""},
+		{name: "maroon", want: "\033[31m"},
+		{name: "Green", want: "\033[32m"},
+		{name: "gray", want: "\033[90m"},
+		{name: "yellow", want: "\033[93m"},
+		{name: "orange", want: "\033[38;2;255;165;0m"},
+		{name: "#b58900", want: "\033[38;2;181;137;0m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ansiColor(tt.name); got != tt.want {
+				t.Errorf("ansiColor(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
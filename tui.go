@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 
@@ -12,22 +13,46 @@ import (
 )
 
 type State struct {
-	searching   bool
-	suggestions bool
-	fuzzySearch bool
+	searching       bool
+	suggestions     bool
+	fuzzySearch     bool
+	history         bool
+	favorites       bool
+	links           bool
+	editingFavorite bool
+	loading         bool
+	conjugation     bool
+	study           bool
+	comparing       bool
+	finding         bool
+	help            bool
+}
+
+// inList reports whether the full-page selection list is being shown
+func (s *State) inList() bool {
+	return s.suggestions || s.fuzzySearch || s.history || s.favorites || s.links
+}
+
+// typing reports whether a form has focus and must receive every rune
+func (s *State) typing() bool {
+	return s.searching || s.editingFavorite || s.finding
 }
 
 type Tui struct {
-	cli *rae.Client
+	cli dictionary
 	app *tview.Application
 
 	// Layout components
 	mainLayout      *tview.Flex
 	header          *tview.TextView
 	footer          *tview.TextView
-	resultsView     *tview.List
+	resultsView     *tview.TreeView
 	suggestionsList *tview.List
 
+	// Outline of the entry shown, beside the results
+	outlineBox *tview.Box
+	outline    *entryOutline
+
 	// Search modal
 	modalContainer *tview.Flex
 	inputField     *tview.InputField
@@ -36,38 +61,184 @@ type Tui struct {
 	// Pages
 	pages *tview.Pages
 
+	// Favorite editor
+	favoriteForm *tview.Form
+
+	// Conjugation page, conjugationMood indexes conjugationMoods plus one,
+	// zero showing every mood
+	conjugationView *tview.TextView
+	conjugations    *rae.Conjugations
+	conjugationVerb string
+	conjugationMood int
+
+	// Study page, studySession is nil while it is closed
+	studyView    *tview.TextView
+	studySession *studySession
+	startStudy   bool
+
+	// Compare page. compareBase is the entry being compared while the search
+	// modal asks for the other word
+	compareLeft    *tview.TextView
+	compareRight   *tview.TextView
+	compareSummary *tview.TextView
+	compareBase    *rae.WordEntry
+
+	// Find inside the results view, find is nil while there is none
+	findField *tview.InputField
+	find      *entryFind
+
+	// Help overlay, helpFocus is what had focus before it was opened
+	helpView  *tview.TextView
+	helpFocus tview.Primitive
+
+	// pendingCopy is sent to the terminal clipboard after the next draw,
+	// toastSeq tells the last toast shown in the footer
+	pendingCopy string
+	toastSeq    uint64
+
 	// State
-	state *State
+	state     *State
+	nav       *navigation
+	history   *lookupHistory
+	favorites *favoriteStore
+	study     *studyDeck
+	cfg       Config
+	// keymap resolves a key to the action bound to it, keys is the reverse
+	keymap map[keyBinding]string
+	keys   map[string]keyBinding
+
+	// In-flight lookup, only touched from the event loop
+	searchSeq    uint64
+	searchWord   string
+	searchCancel context.CancelFunc
+	spinnerFrame int
+
+	// Autocompletion of the search modal, only touched from the event loop
+	completeSeq    uint64
+	completeTimer  *time.Timer
+	completeCancel context.CancelFunc
+	completions    map[string][]string
+	// searchError is the error shown in the search field, never completed
+	searchError string
+}
+
+type TuiOption func(*Tui)
+
+// WithConfig applies colors, sizes and key bindings from cfg
+func WithConfig(cfg Config) TuiOption {
+	return func(t *Tui) {
+		t.cfg = cfg
+	}
+}
+
+// WithHistory records lookups in history and enables the history page
+func WithHistory(history *lookupHistory) TuiOption {
+	return func(t *Tui) {
+		t.history = history
+	}
+}
+
+// WithFavorites enables bookmarking words and the favorites page
+func WithFavorites(favorites *favoriteStore) TuiOption {
+	return func(t *Tui) {
+		t.favorites = favorites
+	}
+}
+
+// WithStudy enables the flashcard study page, reviewing the cards of deck
+func WithStudy(deck *studyDeck) TuiOption {
+	return func(t *Tui) {
+		t.study = deck
+	}
+}
+
+// StartStudying opens the study page instead of the search modal
+func StartStudying() TuiOption {
+	return func(t *Tui) {
+		t.startStudy = true
+	}
 }
 
-func NewTUI(cli *rae.Client) *Tui {
-	return &Tui{
+func NewTUI(cli dictionary, opts ...TuiOption) *Tui {
+	// The widgets take their colors from the theme when created
+	activeTheme.applyTUI()
+
+	t := &Tui{
 		cli:             cli,
 		app:             tview.NewApplication(),
 		mainLayout:      tview.NewFlex(),
 		header:          tview.NewTextView(),
 		footer:          tview.NewTextView(),
-		resultsView:     tview.NewList(),
+		resultsView:     tview.NewTreeView(),
+		outlineBox:      tview.NewBox(),
 		suggestionsList: tview.NewList(),
 		modalContainer:  tview.NewFlex(),
 		inputField:      tview.NewInputField(),
 		form:            tview.NewForm(),
+		favoriteForm:    tview.NewForm(),
+		conjugationView: tview.NewTextView(),
+		studyView:       tview.NewTextView(),
+		compareLeft:     tview.NewTextView(),
+		compareRight:    tview.NewTextView(),
+		compareSummary:  tview.NewTextView(),
+		findField:       tview.NewInputField(),
+		helpView:        tview.NewTextView(),
 		pages:           tview.NewPages(),
 		state:           &State{},
+		nav:             &navigation{},
+		completions:     make(map[string][]string),
+		cfg:             defaultConfig(),
 	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	// Find actions go first so that other actions win when sharing a key
+	bindings := t.cfg.keyBindings()
+	t.keymap = make(map[keyBinding]string, len(bindings))
+	t.keys = make(map[string]keyBinding, len(bindings))
+	for action := range findActions {
+		if binding, ok := parseKey(bindings[action]); ok {
+			t.keymap[binding] = action
+			t.keys[action] = binding
+		}
+	}
+	for action, key := range bindings {
+		if binding, ok := parseKey(key); ok && !findActions[action] {
+			t.keymap[binding] = action
+			t.keys[action] = binding
+		}
+	}
+
+	return t
+}
+
+// key returns the key bound to action, for help texts
+func (t *Tui) key(action string) string {
+	return t.keys[action].String()
+}
+
+// action returns the action bound to the key of event, if any
+func (t *Tui) action(event *tcell.EventKey) string {
+	return t.keymap[bindingOf(event)]
 }
 
 func (t *Tui) Run(ctx context.Context, word fp.Option[string]) {
-	t.state.searching = word.IsNone()
+	t.state.searching = word.IsNone() && !(t.startStudy && t.study != nil)
 	t.setupUI()
 	t.setupPages()
 	t.setupEventHandlers()
 
 	t.pages.SwitchToPage("main")
 
-	if t.state.searching {
-		t.pages.SwitchToPage("modal")
-	} else {
+	// The search modal is shown over the results so the footer stays visible
+	switch {
+	case t.state.searching:
+		t.pages.ShowPage("modal")
+	case word.IsNone():
+		t.showStudy()
+	default:
 		t.search(ctx, word.UnwrapUnsafe())
 	}
 
@@ -78,34 +249,30 @@ func (t *Tui) Run(ctx context.Context, word fp.Option[string]) {
 
 func (t *Tui) setupUI() {
 	// Header
+	t.updateHeader()
 	t.header.
-		SetTextStyle(tcell.StyleDefault.Bold(true)).
-		SetText("Diccionario RAE").
+		SetTextStyle(themeStyle(activeTheme.HeaderText, activeTheme.HeaderBackground).Bold(true)).
 		SetTextAlign(tview.AlignCenter).
 		SetDynamicColors(true).
-		SetTextColor(tcell.ColorWhite).
-		SetBackgroundColor(tcell.ColorGreen)
+		SetBackgroundColor(themeColor(activeTheme.HeaderBackground))
 
 	// Footer
 	t.updateFooter()
 	t.footer.
 		SetTextAlign(tview.AlignCenter).
 		SetDynamicColors(true).
-		SetTextColor(tcell.ColorWhite).
-		SetBackgroundColor(tcell.ColorDarkCyan)
+		SetBackgroundColor(themeColor(activeTheme.FooterBackground))
 
-	// Results view
-	t.resultsView.ShowSecondaryText(false)
-	t.resultsView.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
-		// Optional action when selecting a result
-	})
+	// Results view, the root is hidden so every homonym is a top level node
+	t.resultsView.
+		SetRoot(tview.NewTreeNode("")).
+		SetTopLevel(1).
+		SetGraphicsColor(themeColor(activeTheme.Muted)).
+		SetSelectedFunc(t.selectResult)
 
 	// Suggestions/Fuzzy search list
 	t.suggestionsList.ShowSecondaryText(false)
-	t.suggestionsList.SetSelectedStyle(tcell.StyleDefault.
-		Foreground(tcell.ColorYellow).
-		Background(tcell.ColorDarkBlue).
-		Bold(true))
+	t.suggestionsList.SetSelectedStyle(themeStyle(activeTheme.SelectedText, activeTheme.SelectedBackground).Bold(true))
 	t.suggestionsList.SetSelectedFunc(
 		func(index int, mainText, secondaryText string, shortcut rune) {
 			if secondaryText != "" {
@@ -114,38 +281,54 @@ func (t *Tui) setupUI() {
 		},
 	)
 
+	// Outline sidebar, hidden when its width is zero
+	t.setupOutline()
+	body := tview.NewFlex()
+	if width := t.cfg.UI.OutlineWidth; width > 0 {
+		body.AddItem(t.outlineBox, width, 0, false)
+	}
+	body.AddItem(t.resultsView, 0, 1, true)
+
 	// Main layout
 	t.mainLayout.
 		SetDirection(tview.FlexRow).
 		AddItem(t.header, 1, 1, false).
-		AddItem(t.resultsView, 0, 10, true).
+		AddItem(body, 0, 10, true).
 		AddItem(t.footer, 1, 1, false)
 
 	// Search modal
 	t.setupSearchModal()
+
+	// Favorite tags and note editor
+	t.setupFavoriteForm()
+
+	// Find prompt, shown in place of the footer
+	t.setupFindField()
 }
 
 func (t *Tui) setupSearchModal() {
 	t.modalContainer.SetDirection(tview.FlexRow)
 
 	t.inputField.
-		SetLabel("Buscar: ").
+		SetLabel(tr(searchLabel)).
 		SetFieldWidth(20).
 		SetDoneFunc(func(key tcell.Key) {
 			switch key {
 			case tcell.KeyEscape:
 				t.goBack()
 			case tcell.KeyEnter:
-				t.search(context.Background(), t.inputField.GetText())
+				t.submitSearch(t.inputField.GetText())
 			}
-		})
+		}).
+		SetAutocompleteFunc(t.autocomplete).
+		SetAutocompletedFunc(t.autocompleted)
 
 	t.form.
 		AddFormItem(t.inputField).
-		AddButton("Buscar", func() {
-			t.search(context.Background(), t.inputField.GetText())
+		AddButton(tr("Buscar"), func() {
+			t.submitSearch(t.inputField.GetText())
 		}).
-		AddButton("Limpiar", func() {
+		AddButton(tr("Limpiar"), func() {
 			t.inputField.SetText("")
 		})
 
@@ -170,52 +353,192 @@ func (t *Tui) setupPages() {
 		AddItem(t.suggestionsList, 0, 10, true).
 		AddItem(t.footer, 1, 1, false)
 
+	// Full-page layout for the conjugation tables
+	conjugationLayout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(t.header, 1, 1, false).
+		AddItem(t.conjugationView, 0, 10, true).
+		AddItem(t.footer, 1, 1, false)
+
+	// Full-page layout for the flashcards
+	studyLayout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(t.header, 1, 1, false).
+		AddItem(t.studyView, 0, 10, true).
+		AddItem(t.footer, 1, 1, false)
+
+	// Full-page layout for the comparison, both entries side by side over
+	// what they have in common
+	for _, view := range []*tview.TextView{t.compareLeft, t.compareRight, t.compareSummary} {
+		view.SetDynamicColors(true).SetWrap(true).SetWordWrap(true).SetBorder(true)
+	}
+	t.compareSummary.SetTitle(tr(" En común "))
+	compareLayout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(t.header, 1, 1, false).
+		AddItem(tview.NewFlex().
+			AddItem(t.compareLeft, 0, 1, true).
+			AddItem(t.compareRight, 0, 1, false), 0, 2, true).
+		AddItem(t.compareSummary, 0, 1, false).
+		AddItem(t.footer, 1, 1, false)
+
 	t.pages.
 		AddPage("main", t.mainLayout, true, true).
 		AddPage("modal", modal(t.modalContainer, 40, 10), true, false).
-		AddPage("list", listLayout, true, false)
+		AddPage("list", listLayout, true, false).
+		AddPage("favorite", modal(t.favoriteForm, 60, 9), true, false).
+		AddPage("conjugation", conjugationLayout, true, false).
+		AddPage("study", studyLayout, true, false).
+		AddPage("compare", compareLayout, true, false).
+		AddPage("help", modal(t.helpView, 60, 28), true, false)
 }
 
 func (t *Tui) setupEventHandlers() {
 	t.app.SetInputCapture(t.handleEvent)
+	t.app.SetAfterDrawFunc(t.flushClipboard)
+	t.setupMouse()
+}
+
+func (t *Tui) updateHeader() {
+	text := tr("Diccionario RAE")
+	if t.nav.current != nil {
+		text += " — " + t.nav.current.Word
+		if t.favorites != nil && t.favorites.Has(t.nav.current.Word) {
+			text += " [accent]★[-]"
+		}
+	}
+	if isOffline(t.cli) {
+		text += "  " + activeTheme.badgeTag() + " OFFLINE [-:-:-]"
+	}
+	if t.cfg.UI.Mouse {
+		text += "   " + t.headerButtons()
+	}
+	t.header.SetText(text)
 }
 
 func (t *Tui) updateFooter() {
 	var text string
 	switch {
+	case t.state.loading:
+		text = trf(
+			"[accent]%c[:] Buscando «%s»…  [accent]ESC[:] Cancelar",
+			spinnerFrames[t.spinnerFrame%len(spinnerFrames)],
+			t.searchWord,
+		)
+	case t.state.help:
+		text = trf(
+			"[accent]↑/%s[:] Subir  ↓/%s[:] Bajar  ESC/%s[:] Cerrar  %s[:] Salir",
+			t.key("up"), t.key("down"), t.key("help"), t.key("quit"),
+		)
 	case t.state.suggestions:
-		text = "[yellow]↑/k[:] Subir  ↓/j[:] Bajar  Enter/1-9[:] Seleccionar  q/ESC[:] Volver"
-	case t.state.fuzzySearch:
-		text = "[yellow]↑/k[:] Subir  ↓/j[:] Bajar  Enter[:] Seleccionar  q/ESC[:] Volver"
+		text = trf(
+			"[accent]↑/%s[:] Subir  ↓/%s[:] Bajar  Enter/1-9[:] Seleccionar  ESC[:] Volver  %s[:] Salir",
+			t.key("up"), t.key("down"), t.key("quit"),
+		)
+	case t.state.conjugation:
+		text = trf(
+			"[accent]↑/%s[:] Subir  ↓/%s[:] Bajar  Tab/Shift+Tab[:] Cambiar de modo  ESC/%s[:] Volver  %s[:] Salir",
+			t.key("up"), t.key("down"), t.key("conjugate"), t.key("quit"),
+		)
+	case t.state.study && t.studySession != nil && t.studySession.done():
+		text = trf("[accent]ESC/%s[:] Volver  %s[:] Salir", t.key("study"), t.key("quit"))
+	case t.state.study && t.studySession != nil && t.studySession.revealed:
+		text = tr("[accent]0-2[:] No la sabía  3[:] Difícil  4[:] Bien  5[:] Fácil  ESC[:] Terminar")
+	case t.state.study:
+		text = trf("[accent]Espacio/Enter[:] Mostrar respuesta  ESC/%s[:] Terminar  %s[:] Salir", t.key("study"), t.key("quit"))
+	case t.state.comparing:
+		text = trf(
+			"[accent]↑/%s[:] Subir  ↓/%s[:] Bajar  Tab[:] Cambiar de panel  ESC/%s[:] Volver  %s[:] Salir",
+			t.key("up"), t.key("down"), t.key("compare"), t.key("quit"),
+		)
+	case t.state.fuzzySearch, t.state.history, t.state.favorites, t.state.links:
+		text = trf(
+			"[accent]↑/%s[:] Subir  ↓/%s[:] Bajar  Enter[:] Seleccionar  ESC[:] Volver  %s[:] Salir",
+			t.key("up"), t.key("down"), t.key("quit"),
+		)
+	case t.state.editingFavorite:
+		text = tr("[accent]Tab[:] Siguiente campo  Enter[:] Confirmar  ESC[:] Cancelar")
+	case t.state.searching && t.compareBase != nil:
+		text = tr("[accent]Enter[:] Comparar  ESC[:] Cancelar")
 	case t.state.searching:
-		text = "[yellow]Enter[:] Buscar  ESC[:] Cancelar"
+		text = tr("[accent]Enter[:] Buscar  ESC[:] Cancelar")
+	case t.find != nil:
+		text = trf(
+			"%s  [accent]%s[:] Siguiente  %s[:] Anterior  %s[:] Buscar otra vez  ESC[:] Terminar  %s[:] Salir",
+			t.findStatus(), t.key("find_next"), t.key("find_previous"), t.key("find"), t.key("quit"),
+		)
 	default:
-		text = "[yellow]↑/k[:] Subir  ↓/j[:] Bajar  n[:] Nueva búsqueda  q/ESC[:] Salir"
+		text = trf(
+			"[accent]↑/%s[:] Subir  ↓/%s[:] Bajar  Enter[:] Ir a palabra  Espacio[:] Plegar  %s[:] Plegar todo  %s[:] Desplegar todo  ←/%s[:] Atrás  →/%s[:] Adelante  "+
+				"%s[:] Historial  %s[:] Favorito  %s[:] Etiquetas  %s[:] Favoritos  %s[:] Conjugar  %s[:] Palabra del día  %s[:] Estudiar  %s[:] Exportar  %s[:] Copiar  %s[:] Comparar  %s[:] Buscar en la entrada  %s[:] Nueva búsqueda  %s[:] Ayuda  %s[:] Salir",
+			t.key("up"), t.key("down"), t.key("fold_all"), t.key("unfold_all"), t.key("back"), t.key("forward"),
+			t.key("history"), t.key("favorite"), t.key("edit_favorite"), t.key("favorites"),
+			t.key("conjugate"), t.key("word_of_day"), t.key("study"), t.key("export"), t.key("copy_definition"), t.key("compare"), t.key("find"), t.key("new_search"), t.key("help"), t.key("quit"),
+		)
 	}
 	t.footer.SetText(text)
-	t.footer.SetTextStyle(tcell.StyleDefault.Bold(true))
+	t.footer.SetTextStyle(themeStyle(activeTheme.FooterText, activeTheme.FooterBackground).Bold(true))
 }
 
 func (t *Tui) resetState() {
 	t.state.searching = false
 	t.state.suggestions = false
 	t.state.fuzzySearch = false
+	t.state.history = false
+	t.state.favorites = false
+	t.state.links = false
+	t.state.editingFavorite = false
+	t.state.conjugation = false
+	t.state.study = false
+	t.state.comparing = false
 	t.updateFooter()
 }
 
 func (t *Tui) goBack() {
 	switch {
-	case t.state.suggestions, t.state.fuzzySearch:
+	case t.state.loading:
+		t.cancelSearch()
+	case t.state.inList():
 		t.resetState()
 		t.pages.SwitchToPage("main")
 	case t.state.searching:
+		t.endCompareInput()
 		t.resetState()
 		t.pages.SwitchToPage("main")
+	case t.state.editingFavorite:
+		t.closeFavoriteForm()
+	case t.state.conjugation:
+		t.closeConjugation()
+	case t.state.study:
+		t.closeStudy()
+	case t.state.comparing:
+		t.closeComparison()
+	case t.state.finding:
+		t.cancelFind()
+	case t.find != nil:
+		t.clearFind()
+	case t.nav.CanGoBack():
+		t.historyBack()
 	default:
 		t.exit()
 	}
 }
 
+// openSearch shows the search modal over the results, leaving the list being
+// shown if any
+func (t *Tui) openSearch() {
+	if t.state.inList() {
+		t.resetState()
+		t.pages.SwitchToPage("main")
+	}
+	t.state.searching = true
+	t.searchError = ""
+	t.inputField.SetText("") // Clear input
+	t.updateFooter()
+	t.pages.ShowPage("modal")
+	t.app.SetFocus(t.inputField) // Set focus to input field
+}
+
 func (t *Tui) exit() {
 	t.app.Stop()
 }
@@ -225,79 +548,37 @@ func (t *Tui) selectWord(word string) {
 	t.search(context.Background(), word)
 }
 
-func (t *Tui) search(ctx context.Context, word string) {
-	res, err := t.cli.Word(ctx, word)
-	if err != nil {
-		if len(res.Suggestions) > 0 {
-			t.showSuggestions(res.Suggestions)
+// showLookup presents the outcome of a finished lookup
+func (t *Tui) showLookup(out lookupOutcome) {
+	defer t.updateHeader()
+
+	if out.err != nil {
+		if len(out.entry.Suggestions) > 0 {
+			t.showSuggestions(out.entry.Suggestions)
 			return
 		}
-		t.showFuzzySearchResults(ctx, word)
+		t.showFuzzySearchResults(out.searchResults, out.searchErr)
 		return
 	}
 
-	t.displayResults(res)
+	t.visit(out.entry)
+	if out.lemma != nil {
+		note := tview.NewTreeNode("[muted]" + tview.Escape(out.lemma.Describe()))
+		root := t.resultsView.GetRoot()
+		root.SetChildren(append([]*tview.TreeNode{note}, root.GetChildren()...))
+		t.resultsView.SetCurrentNode(note)
+	}
 }
 
 func (t *Tui) displayResults(res rae.WordEntry) {
 	t.resetState()
-	t.resultsView.Clear()
-
-	for _, meaning := range res.Meanings {
-		if meaning.HomonymIndex > 0 {
-			t.resultsView.AddItem(
-				fmt.Sprintf("[green][::b]%s%s[white]", res.Word, superscript(meaning.HomonymIndex)),
-				"", 0, nil,
-			)
-			t.resultsView.AddItem("", "", 0, nil)
-		}
-
-		if meaning.Origin != nil && meaning.Origin.Raw != "" {
-			t.resultsView.AddItem(
-				fmt.Sprintf("[yellow][::b]Origen:[white] %s", meaning.Origin.Raw),
-				"", 0, nil,
-			)
-			t.resultsView.AddItem("", "", 0, nil)
-		}
-
-		for _, def := range meaning.Definitions {
-			t.resultsView.AddItem(def.Raw, "", 0, nil)
-
-			if len(def.Examples) > 0 {
-				for _, ex := range def.Examples {
-					t.resultsView.AddItem(fmt.Sprintf("    [gray]↳ %s", ex), "", 0, nil)
-				}
-			}
-
-			if len(def.SynonymsV2) > 0 {
-				syns := formatRelatedWords(def.SynonymsV2)
-				t.resultsView.AddItem(fmt.Sprintf("    [cyan]Sin.:[white] %s", syns), "", 0, nil)
-			}
-			if len(def.AntonymsV2) > 0 {
-				ants := formatRelatedWords(def.AntonymsV2)
-				t.resultsView.AddItem(fmt.Sprintf("    [red]Ant.:[white] %s", ants), "", 0, nil)
-			}
-		}
-
-		if len(meaning.Locutions) > 0 {
-			t.resultsView.AddItem("", "", 0, nil)
-			t.resultsView.AddItem("[yellow][::b]Locuciones[white]", "", 0, nil)
-			for _, loc := range meaning.Locutions {
-				t.resultsView.AddItem(fmt.Sprintf("  [green][::b]%s[white]", loc.Expression), "", 0, nil)
-				for _, sense := range loc.Senses {
-					t.resultsView.AddItem(fmt.Sprintf("    %s", sense.Raw), "", 0, nil)
-				}
-			}
-		}
-
-		if meaning.Conjugations != nil {
-			t.resultsView.AddItem("", "", 0, nil)
-			t.resultsView.AddItem("[::b]Conjugaciones:", "", 0, nil)
-			t.resultsView.AddItem("", "", 0, nil)
-			renderConjugations(meaning.Conjugations, t.resultsView)
-		}
+	t.find = nil
 
-		t.resultsView.AddItem("", "", 0, nil)
+	root, outline := t.entryTree(res)
+	t.resultsView.SetRoot(root)
+	t.outline = outline
+	if children := root.GetChildren(); len(children) > 0 {
+		t.resultsView.SetCurrentNode(children[0])
 	}
 
 	t.pages.SwitchToPage("main")
@@ -320,9 +601,9 @@ func formatRelatedWords(words []rae.RelatedWord) string {
 	parts := make([]string, len(words))
 	for i, w := range words {
 		if w.Label != "" {
-			parts[i] = fmt.Sprintf("%s [gray](%s)[white]", w.Word, w.Label)
+			parts[i] = fmt.Sprintf("[::u]%s[::-] [muted](%s)[-]", w.Word, w.Label)
 		} else {
-			parts[i] = w.Word
+			parts[i] = fmt.Sprintf("[::u]%s[::-]", w.Word)
 		}
 	}
 	return fmt.Sprintf("%s", joinStrings(parts, ", "))
@@ -345,7 +626,7 @@ func (t *Tui) showSuggestions(suggestions []string) {
 	t.updateFooter()
 
 	t.suggestionsList.Clear()
-	t.suggestionsList.AddItem("[yellow]¿Quisiste decir?", "", 0, nil)
+	t.suggestionsList.AddItem(tr("[accent]¿Quisiste decir?"), "", 0, nil)
 	t.suggestionsList.AddItem("", "", 0, nil)
 
 	for i, suggestion := range suggestions {
@@ -354,25 +635,24 @@ func (t *Tui) showSuggestions(suggestions []string) {
 	}
 
 	t.suggestionsList.AddItem("", "", 0, nil)
-	t.suggestionsList.AddItem("0. Cancelar", "", '0', nil)
+	t.suggestionsList.AddItem(tr("0. Cancelar"), "", '0', nil)
 
 	t.pages.SwitchToPage("list")
 }
 
-func (t *Tui) showFuzzySearchResults(ctx context.Context, word string) {
+func (t *Tui) showFuzzySearchResults(searchResults []rae.SearchResult, err error) {
 	t.resetState()
 	t.state.fuzzySearch = true
 	t.updateFooter()
 
-	searchResults, err := t.cli.Search(ctx, word)
 	if err != nil || len(searchResults) == 0 {
 		t.resetState()
-		t.showError("No se encontraron resultados de búsqueda difusa")
+		t.showError(tr("No se encontraron resultados de búsqueda difusa"))
 		return
 	}
 
 	t.suggestionsList.Clear()
-	t.suggestionsList.AddItem("[yellow]Búsqueda difusa - Resultados encontrados:", "", 0, nil)
+	t.suggestionsList.AddItem(tr("[accent]Búsqueda difusa - Resultados encontrados:"), "", 0, nil)
 	t.suggestionsList.AddItem("", "", 0, nil)
 
 	for _, result := range searchResults {
@@ -382,14 +662,10 @@ func (t *Tui) showFuzzySearchResults(ctx context.Context, word string) {
 		var text string
 		if err == nil && wordEntry != nil && len(wordEntry.Meanings) > 0 &&
 			len(wordEntry.Meanings[0].Definitions) > 0 {
-			def := wordEntry.Meanings[0].Definitions[0].Raw
-			preview := def
-			if len(def) > 70 {
-				preview = def[:70] + "..."
-			}
-			text = fmt.Sprintf("[yellow][::b]%s[white] - %s", searchWord, preview)
+			preview := truncate(wordEntry.Meanings[0].Definitions[0].Raw, t.cfg.UI.TUIPreviewLength)
+			text = fmt.Sprintf("[accent][::b]%s[-] - %s", searchWord, preview)
 		} else {
-			text = fmt.Sprintf("[yellow][::b]%s", searchWord)
+			text = fmt.Sprintf("[accent][::b]%s", searchWord)
 		}
 
 		t.suggestionsList.AddItem(text, searchWord, 0, nil)
@@ -399,21 +675,41 @@ func (t *Tui) showFuzzySearchResults(ctx context.Context, word string) {
 }
 
 func (t *Tui) showError(message string) {
+	t.searchError = message
 	t.inputField.SetText(message)
-	t.modalContainer.SetBackgroundColor(tcell.ColorRed)
+	t.modalContainer.SetBackgroundColor(themeColor(activeTheme.Error))
 	t.state.searching = true
 	t.updateFooter()
-	t.pages.SwitchToPage("modal")
+	t.pages.SwitchToPage("main")
+	t.pages.ShowPage("modal")
 }
 
 func (t *Tui) handleEvent(event *tcell.EventKey) *tcell.EventKey {
+	if t.state.help {
+		return t.handleHelpEvent(event)
+	}
+	if !t.state.typing() && t.action(event) == "help" {
+		t.showHelp()
+		return nil
+	}
+
+	if t.state.conjugation {
+		return t.handleConjugationEvent(event)
+	}
+	if t.state.study {
+		return t.handleStudyEvent(event)
+	}
+	if t.state.comparing {
+		return t.handleCompareEvent(event)
+	}
+
 	switch event.Key() {
 	case tcell.KeyEscape:
 		t.goBack()
 		return nil
 
 	case tcell.KeyEnter:
-		if t.state.suggestions || t.state.fuzzySearch {
+		if t.state.inList() {
 			_, selectedWord := t.suggestionsList.GetItemText(t.suggestionsList.GetCurrentItem())
 			if selectedWord != "" {
 				t.selectWord(selectedWord)
@@ -422,91 +718,210 @@ func (t *Tui) handleEvent(event *tcell.EventKey) *tcell.EventKey {
 		}
 
 	case tcell.KeyUp:
-		if t.state.suggestions || t.state.fuzzySearch {
+		if t.state.inList() {
 			idx := t.suggestionsList.GetCurrentItem()
 			if idx > 0 {
 				t.suggestionsList.SetCurrentItem(idx - 1)
 			}
 			return nil
-		} else if !t.state.searching {
-			idx := t.resultsView.GetCurrentItem()
-			if idx > 0 {
-				t.resultsView.SetCurrentItem(idx - 1)
-			}
+		} else if !t.state.typing() {
+			t.resultsView.Move(-1)
 			return nil
 		}
 
 	case tcell.KeyDown:
-		if t.state.suggestions || t.state.fuzzySearch {
+		if t.state.inList() {
 			idx := t.suggestionsList.GetCurrentItem()
 			if idx < t.suggestionsList.GetItemCount()-1 {
 				t.suggestionsList.SetCurrentItem(idx + 1)
 			}
 			return nil
-		} else if !t.state.searching {
-			idx := t.resultsView.GetCurrentItem()
-			if idx < t.resultsView.GetItemCount()-1 {
-				t.resultsView.SetCurrentItem(idx + 1)
-			}
+		} else if !t.state.typing() {
+			t.resultsView.Move(1)
+			return nil
+		}
+
+	case tcell.KeyLeft:
+		if !t.state.typing() && !t.state.inList() {
+			t.historyBack()
+			return nil
+		}
+
+	case tcell.KeyRight:
+		if !t.state.typing() && !t.state.inList() {
+			t.historyForward()
 			return nil
 		}
 
 	case tcell.KeyRune:
 		// If searching, let the input field handle all runes (don't intercept 'q')
-		if t.state.searching {
+		if t.state.typing() {
 			// Let the input field handle all runes normally
 			return event
 		}
 
-		return t.handleRune(event.Rune())
+		return t.handleKey(event)
+	}
+
+	// Special keys such as Ctrl-N may be bound to actions too
+	if !t.state.typing() && t.action(event) != "" {
+		return t.handleKey(event)
 	}
 
 	return event
 }
 
-func (t *Tui) handleRune(r rune) *tcell.EventKey {
-	switch r {
-	case 'q':
+// handleKey runs the action bound to the key of event outside of forms
+func (t *Tui) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	binding := bindingOf(event)
+
+	// While finding inside the entry, its actions win over those sharing
+	// their keys
+	if t.find != nil && !t.state.inList() {
+		switch binding {
+		case t.keys["find_next"]:
+			t.stepFind(1)
+			return nil
+		case t.keys["find_previous"]:
+			t.stepFind(-1)
+			return nil
+		}
+	}
+
+	switch t.keymap[binding] {
+	case "quit":
 		t.exit()
 		return nil
 
-	case 'j':
-		if t.state.suggestions || t.state.fuzzySearch {
+	case "down":
+		if t.state.inList() {
 			idx := t.suggestionsList.GetCurrentItem()
 			if idx < t.suggestionsList.GetItemCount()-1 {
 				t.suggestionsList.SetCurrentItem(idx + 1)
 			}
 			return nil
 		}
-		idx := t.resultsView.GetCurrentItem()
-		if idx < t.resultsView.GetItemCount()-1 {
-			t.resultsView.SetCurrentItem(idx + 1)
-		}
+		t.resultsView.Move(1)
 		return nil
 
-	case 'k':
-		if t.state.suggestions || t.state.fuzzySearch {
+	case "up":
+		if t.state.inList() {
 			idx := t.suggestionsList.GetCurrentItem()
 			if idx > 0 {
 				t.suggestionsList.SetCurrentItem(idx - 1)
 			}
 			return nil
 		}
-		idx := t.resultsView.GetCurrentItem()
-		if idx > 0 {
-			t.resultsView.SetCurrentItem(idx - 1)
+		t.resultsView.Move(-1)
+		return nil
+
+	case "back":
+		if !t.state.inList() {
+			t.historyBack()
+		}
+		return nil
+
+	case "forward":
+		if !t.state.inList() {
+			t.historyForward()
+		}
+		return nil
+
+	case "history":
+		t.showHistory()
+		return nil
+
+	case "favorite":
+		if !t.state.inList() {
+			t.toggleFavorite()
+		}
+		return nil
+
+	case "edit_favorite":
+		if !t.state.inList() {
+			t.editFavorite()
+		}
+		return nil
+
+	case "favorites":
+		t.showFavorites()
+		return nil
+
+	case "conjugate":
+		if !t.state.inList() && t.nav.current != nil {
+			if conjugations := entryConjugations(*t.nav.current); conjugations != nil {
+				t.showConjugation(t.nav.current.Word, conjugations)
+			}
+		}
+		return nil
+
+	case "word_of_day":
+		t.showWordOfDay()
+		return nil
+
+	case "study":
+		t.showStudy()
+		return nil
+
+	case "export":
+		if !t.state.inList() {
+			t.exportCurrent()
+		}
+		return nil
+
+	case "compare":
+		if !t.state.inList() {
+			t.startCompare()
+		}
+		return nil
+
+	case "find":
+		if !t.state.inList() {
+			t.startFind()
+		}
+		return nil
+
+	case "copy_definition", "copy_entry", "copy_markdown", "copy_word":
+		if !t.state.inList() {
+			t.copyCurrent(t.keymap[binding])
+		}
+		return nil
+
+	case "half_page_down", "half_page_up", "top", "bottom",
+		"next_sense", "previous_sense", "next_homonym", "previous_homonym", "goto_locutions", "goto_conjugation":
+		if !t.state.inList() {
+			t.navigateResults(t.keymap[binding])
 		}
 		return nil
 
-	case 'n':
-		t.state.searching = true
-		t.inputField.SetText("") // Clear input
-		t.updateFooter()
-		t.pages.SwitchToPage("modal")
-		t.app.SetFocus(t.inputField) // Set focus to input field
+	case "fold_all":
+		if !t.state.inList() {
+			t.foldAll()
+		}
+		return nil
+
+	case "unfold_all":
+		if !t.state.inList() {
+			t.unfoldAll()
+		}
+		return nil
+
+	case "new_search":
+		t.openSearch()
 		return nil
 
 	default:
+		if event.Key() != tcell.KeyRune {
+			return nil
+		}
+		r := event.Rune()
+
+		// Space folds or unfolds the selected node of the results
+		if r == ' ' && !t.state.inList() {
+			t.toggleFold()
+			return nil
+		}
+
 		// Handle number selection for suggestions only (not fuzzy search)
 		if t.state.suggestions && r >= '0' && r <= '9' {
 			num := int(r - '0')
@@ -528,84 +943,3 @@ func (t *Tui) handleRune(r rune) *tcell.EventKey {
 
 	return nil
 }
-
-func renderConjugations(conjugations *rae.Conjugations, resultsView *tview.List) {
-	if np := conjugations.ConjugationNonPersonal; (np != rae.ConjugationNonPersonal{}) {
-		resultsView.AddItem("[yellow][::b]Formas no personales[white]", "", 0, nil)
-		resultsView.AddItem(
-			fmt.Sprintf(
-				"  [cyan]Infinitivo:[white] %s  [cyan]Participio:[white] %s  [cyan]Gerundio:[white] %s",
-				np.Infinitive,
-				np.Participle,
-				np.Gerund,
-			),
-			"",
-			0,
-			nil,
-		)
-		resultsView.AddItem("", "", 0, nil)
-	}
-
-	if ind := conjugations.ConjugationIndicative; (ind != rae.ConjugationIndicative{}) {
-		resultsView.AddItem("[yellow][::b]Modo Indicativo[white]", "", 0, nil)
-		renderVerbalConjugation("Presente", ind.Present, resultsView)
-		renderVerbalConjugation("Pretérito Imperfecto", ind.Imperfect, resultsView)
-		renderVerbalConjugation("Pretérito Perfecto Simple", ind.Preterite, resultsView)
-		renderVerbalConjugation("Futuro", ind.Future, resultsView)
-		renderVerbalConjugation("Condicional", ind.Conditional, resultsView)
-		resultsView.AddItem("", "", 0, nil)
-	}
-
-	if subj := conjugations.ConjugationSubjunctive; (subj != rae.ConjugationSubjunctive{}) {
-		resultsView.AddItem("[yellow][::b]Modo Subjuntivo[white]", "", 0, nil)
-		renderVerbalConjugation("Presente", subj.Present, resultsView)
-		renderVerbalConjugation("Pretérito Imperfecto", subj.Imperfect, resultsView)
-		renderVerbalConjugation("Futuro", subj.Future, resultsView)
-		resultsView.AddItem("", "", 0, nil)
-	}
-
-	if imp := conjugations.ConjugationImperative; (imp != rae.ConjugationImperative{}) {
-		resultsView.AddItem("[yellow][::b]Modo Imperativo[white]", "", 0, nil)
-		resultsView.AddItem(
-			fmt.Sprintf("  [cyan]Tú:[white] %s  [cyan]Vosotros:[white] %s",
-				imp.SingularSecondPerson, imp.PluralSecondPerson),
-			"",
-			0,
-			nil,
-		)
-	}
-}
-
-func renderVerbalConjugation(title string, conj rae.Conjugation, resultsView *tview.List) {
-	resultsView.AddItem(fmt.Sprintf("  [cyan][::b]%s[white]", title), "", 0, nil)
-
-	// Primera persona singular y plural en una línea
-	resultsView.AddItem(
-		fmt.Sprintf("    [yellow]Yo:[white] %-25s  [yellow]Nosotros:[white] %s",
-			conj.SingularFirstPerson, conj.PluralFirstPerson),
-		"",
-		0,
-		nil,
-	)
-
-	// Segunda persona singular y plural
-	resultsView.AddItem(
-		fmt.Sprintf("    [yellow]Tú:[white] %-25s  [yellow]Vosotros:[white] %s",
-			conj.SingularSecondPerson, conj.PluralSecondPerson),
-		"",
-		0,
-		nil,
-	)
-
-	// Tercera persona singular y plural
-	resultsView.AddItem(
-		fmt.Sprintf(
-			"    [yellow]Él/Ella/Usted:[white] %-15s  [yellow]Ellos/Ellas/Ustedes:[white] %s",
-			conj.SingularThirdPerson,
-			conj.PluralThirdPerson,
-		),
-		"",
-		0,
-		nil,
-	)
-}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+const (
+	// autocompleteDelay is the default debounce of API searches while typing
+	autocompleteDelay = 250 * time.Millisecond
+	// autocompleteMinLength is the shortest text worth completing
+	autocompleteMinLength = 2
+	// autocompleteLimit caps the entries of the drop-down
+	autocompleteLimit = 10
+)
+
+// autocomplete feeds the search modal drop-down. Words from history and
+// favorites are offered right away, while API results are fetched in the
+// background and shown once they arrive, if the text has not changed. An
+// error shown in the field is not completed
+func (t *Tui) autocomplete(text string) []string {
+	t.completeSeq++
+
+	if t.searchError != "" && text == t.searchError {
+		t.stopAutocomplete()
+		return nil
+	}
+
+	text = strings.TrimSpace(text)
+	if len([]rune(text)) < autocompleteMinLength {
+		t.stopAutocomplete()
+		return nil
+	}
+
+	local := t.localCompletions(text)
+	if remote, ok := t.completions[cacheKey(text)]; ok {
+		return mergeCompletions(local, remote)
+	}
+
+	t.scheduleCompletion(text, t.completeSeq)
+	return local
+}
+
+// autocompleted looks up the entry picked from the drop-down. Navigating the
+// drop-down leaves the typed text alone
+func (t *Tui) autocompleted(text string, index, source int) bool {
+	if source == tview.AutocompletedNavigate {
+		return false
+	}
+
+	t.stopAutocomplete()
+	t.inputField.SetText(text)
+	t.search(context.Background(), text)
+	return true
+}
+
+func (t *Tui) scheduleCompletion(text string, seq uint64) {
+	t.stopAutocomplete()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.completeCancel = cancel
+	t.completeTimer = time.AfterFunc(t.cfg.UI.AutocompleteDelay, func() {
+		results, err := t.cli.Search(ctx, text)
+		if err != nil || ctx.Err() != nil {
+			return
+		}
+
+		words := make([]string, 0, len(results))
+		for _, result := range results {
+			words = append(words, result.Doc.Word)
+		}
+
+		t.app.QueueUpdateDraw(func() {
+			t.completions[cacheKey(text)] = words
+
+			// Only the most recent keystroke may open the drop-down
+			if seq != t.completeSeq || !t.state.searching {
+				return
+			}
+			if cacheKey(t.inputField.GetText()) == cacheKey(text) {
+				t.inputField.Autocomplete()
+			}
+		})
+	})
+}
+
+func (t *Tui) stopAutocomplete() {
+	if t.completeTimer != nil {
+		t.completeTimer.Stop()
+		t.completeTimer = nil
+	}
+	if t.completeCancel != nil {
+		t.completeCancel()
+		t.completeCancel = nil
+	}
+}
+
+// localCompletions returns favorites and recently looked up words starting
+// with text
+func (t *Tui) localCompletions(text string) []string {
+	prefix := cacheKey(text)
+
+	var words []string
+	if t.favorites != nil {
+		for _, item := range t.favorites.List() {
+			if strings.HasPrefix(cacheKey(item.Word), prefix) {
+				words = append(words, item.Word)
+			}
+		}
+	}
+	if t.history != nil {
+		for _, entry := range t.history.Recent(t.cfg.UI.HistorySize) {
+			if strings.HasPrefix(cacheKey(entry.Word), prefix) {
+				words = append(words, entry.Word)
+			}
+		}
+	}
+
+	return mergeCompletions(words, nil)
+}
+
+func mergeCompletions(local, remote []string) []string {
+	merged := uniqueWords(append(append([]string(nil), local...), remote...))
+	if len(merged) > autocompleteLimit {
+		merged = merged[:autocompleteLimit]
+	}
+	return merged
+}
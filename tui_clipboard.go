@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// toastDuration is how long a toast stays in the footer
+const toastDuration = 2 * time.Second
+
+// clipboardTimeout bounds how long a clipboard tool may take
+const clipboardTimeout = 3 * time.Second
+
+// copyCurrent copies the headword, the sense holding the selection or the
+// whole entry, as plain text or Markdown, depending on action
+func (t *Tui) copyCurrent(action string) {
+	if t.nav.current == nil {
+		return
+	}
+	entry := *t.nav.current
+
+	switch action {
+	case "copy_word":
+		t.copyToClipboard(entry.Word, fmt.Sprintf("«%s»", entry.Word))
+	case "copy_definition":
+		t.copyToClipboard(t.selectedDefinition(), tr("la acepción"))
+	case "copy_entry":
+		t.copyToClipboard(entryText(entry), tr("la entrada"))
+	case "copy_markdown":
+		var b strings.Builder
+		if err := exportMarkdown(&b, []exportEntry{{Entry: entry}}); err != nil {
+			t.toast(tr("[error]No se pudo copiar: ") + tview.Escape(err.Error()))
+			return
+		}
+		t.copyToClipboard(b.String(), tr("la entrada en Markdown"))
+	}
+}
+
+// selectedDefinition returns the text of the sense holding the selected node
+// of the results, or of the node itself outside the senses
+func (t *Tui) selectedDefinition() string {
+	node := t.resultsView.GetCurrentNode()
+	if node == nil {
+		return ""
+	}
+	if t.outline != nil {
+		path := t.resultsView.GetPath(node)
+		for i := len(path) - 1; i >= 0; i-- {
+			if slices.Contains(t.outline.senses, path[i]) {
+				node = path[i]
+				break
+			}
+		}
+	}
+
+	text := plainText(node.GetText())
+	text = strings.TrimPrefix(text, markerExpanded)
+	text = strings.TrimPrefix(text, markerCollapsed)
+	return strings.TrimSpace(text)
+}
+
+// copyToClipboard copies text, described by what in the toast confirming it.
+// The terminal gets it through OSC 52 once the screen is drawn, and the
+// system clipboard too when a tool for it is installed, as not every terminal
+// supports OSC 52. The tool runs off the event loop so that a slow one never
+// freezes the UI
+func (t *Tui) copyToClipboard(text, what string) {
+	if text == "" {
+		return
+	}
+	t.pendingCopy = text
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), clipboardTimeout)
+		defer cancel()
+		tool, err := copyWithCommand(ctx, text)
+
+		t.app.QueueUpdateDraw(func() {
+			switch {
+			case err != nil:
+				t.toast(trf("[accent]Se copió %s solo en el terminal: %s", what, tview.Escape(err.Error())))
+			case tool != "":
+				t.toast(trf("[success]Se copió %s al portapapeles (%s)", what, tool))
+			default:
+				t.toast(trf("[success]Se copió %s al portapapeles", what))
+			}
+		})
+	}()
+}
+
+// flushClipboard sends the text waiting to be copied to the terminal, after
+// drawing so that the sequence never lands in the middle of the screen output
+func (t *Tui) flushClipboard(screen tcell.Screen) {
+	if t.pendingCopy == "" {
+		return
+	}
+	text := t.pendingCopy
+	t.pendingCopy = ""
+	if tty, ok := screen.Tty(); ok {
+		io.WriteString(tty, osc52(text))
+	}
+}
+
+// toast shows text in the footer for toastDuration, then the footer of the
+// page again unless another toast replaced it
+func (t *Tui) toast(text string) {
+	t.toastSeq++
+	seq := t.toastSeq
+	t.footer.SetText(text)
+	time.AfterFunc(toastDuration, func() {
+		t.app.QueueUpdateDraw(func() {
+			if seq == t.toastSeq {
+				t.updateFooter()
+			}
+		})
+	})
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	rae "github.com/rae-api-com/go-rae"
+	"github.com/rivo/tview"
+)
+
+const (
+	searchLabel  = "Buscar: "
+	compareLabel = "Comparar con: "
+)
+
+var tviewCompareStyles = map[compareStyle]string{
+	compareTitle:   "[title::b]",
+	compareHeading: "[::b]",
+	compareMuted:   "[muted]",
+	compareShared:  "[accent]",
+	compareDiffers: "[info]",
+}
+
+func tviewCompareText(lines []compareLine) string {
+	var text strings.Builder
+	for _, line := range lines {
+		if style := tviewCompareStyles[line.style]; style != "" {
+			text.WriteString(style + tview.Escape(line.text) + "[-::-]\n")
+		} else {
+			text.WriteString(tview.Escape(line.text) + "\n")
+		}
+	}
+	return text.String()
+}
+
+// startCompare asks in the search modal for the word to compare the current
+// entry with
+func (t *Tui) startCompare() {
+	if t.nav.current == nil {
+		return
+	}
+	base := *t.nav.current
+	t.compareBase = &base
+
+	t.resetState()
+	t.state.searching = true
+	t.inputField.SetLabel(tr(compareLabel)).SetText("")
+	t.updateFooter()
+	t.pages.ShowPage("modal")
+	t.app.SetFocus(t.inputField)
+}
+
+// endCompareInput turns the search modal back into a plain search
+func (t *Tui) endCompareInput() {
+	t.compareBase = nil
+	t.inputField.SetLabel(tr(searchLabel))
+}
+
+// submitSearch runs what the search modal was opened for
+func (t *Tui) submitSearch(word string) {
+	if t.compareBase == nil {
+		t.search(context.Background(), word)
+		return
+	}
+
+	base := *t.compareBase
+	t.startLookup(context.Background(), word, func(out lookupOutcome) {
+		if out.err != nil {
+			// The modal stays in compare mode to try another word
+			message := trf("No se encontró «%s»", word)
+			if len(out.entry.Suggestions) > 0 {
+				message += trf(": ¿%s?", strings.Join(out.entry.Suggestions, ", "))
+			}
+			t.showError(message)
+			return
+		}
+		t.endCompareInput()
+		t.showComparison(base, out.entry)
+	})
+}
+
+// showComparison shows left and right side by side over what they have in
+// common
+func (t *Tui) showComparison(left, right rae.WordEntry) {
+	t.resetState()
+	t.state.comparing = true
+
+	cmp := compareEntries(left, right)
+	t.compareLeft.SetText(tviewCompareText(comparisonSide(left, right.Word, cmp, true))).ScrollToBeginning()
+	t.compareRight.SetText(tviewCompareText(comparisonSide(right, left.Word, cmp, false))).ScrollToBeginning()
+	// The box of the summary is already titled
+	summary := comparisonSummary(left.Word, right.Word, cmp)[1:]
+	t.compareSummary.SetText(tviewCompareText(summary)).ScrollToBeginning()
+	t.updateFooter()
+
+	t.pages.SwitchToPage("compare")
+	t.app.SetFocus(t.compareLeft)
+}
+
+func (t *Tui) closeComparison() {
+	t.resetState()
+	t.pages.SwitchToPage("main")
+	t.app.SetFocus(t.resultsView)
+}
+
+// handleCompareEvent cycles through the panes with Tab, leaving the rest to
+// the pane with focus so that arrows and page keys scroll it
+func (t *Tui) handleCompareEvent(event *tcell.EventKey) *tcell.EventKey {
+	panes := []*tview.TextView{t.compareLeft, t.compareRight, t.compareSummary}
+	current := max(slices.IndexFunc(panes, func(p *tview.TextView) bool { return p.HasFocus() }), 0)
+	focused := panes[current]
+
+	switch event.Key() {
+	case tcell.KeyEscape:
+		t.closeComparison()
+		return nil
+
+	case tcell.KeyTab:
+		t.app.SetFocus(panes[(current+1)%len(panes)])
+		return nil
+
+	case tcell.KeyBacktab:
+		t.app.SetFocus(panes[(current+len(panes)-1)%len(panes)])
+		return nil
+
+	}
+
+	row, col := focused.GetScrollOffset()
+	switch t.action(event) {
+	case "quit":
+		t.exit()
+	case "compare":
+		t.closeComparison()
+	case "down":
+		focused.ScrollTo(row+1, col)
+	case "up":
+		focused.ScrollTo(max(row-1, 0), col)
+	default:
+		if event.Key() != tcell.KeyRune {
+			return event
+		}
+	}
+	return nil
+}
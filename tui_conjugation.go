@@ -0,0 +1,131 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	rae "github.com/rae-api-com/go-rae"
+	"github.com/rivo/tview"
+)
+
+var tviewConjugationStyle = conjugationStyle{
+	mood:   func(s string) string { return "[title::b]" + s + "[-::-]" },
+	tense:  func(s string) string { return "[::b]" + s + "[::-]" },
+	person: func(s string) string { return "[info]" + s + "[-]" },
+}
+
+// conjugationNode lists the conjugation of a verb in the results tree, a
+// branch per mood. Only the non-personal forms are unfolded at first, and
+// the first child opens the full tables when selected
+func (t *Tui) conjugationNode(verb string, conjugations *rae.Conjugations) *tview.TreeNode {
+	node := tview.NewTreeNode(tr("[accent][::b]Conjugación[-]"))
+	node.AddChild(tview.NewTreeNode(
+		trf("[muted]Enter, %s o doble clic: ver todos los modos y tiempos", tview.Escape(t.key("conjugate"))),
+	).SetSelectedFunc(func() { t.showConjugation(verb, conjugations) }))
+
+	var mood *tview.TreeNode
+	last := ""
+	for _, table := range conjugationTables(conjugations, conjugationFilter{}) {
+		if mood == nil || table.Mood != last {
+			mood = tview.NewTreeNode(tviewConjugationStyle.mood(table.moodLabel)).
+				SetExpanded(table.Mood == "non_personal")
+			node.AddChild(mood)
+			last = table.Mood
+		}
+
+		// Non-personal forms and the imperative hang from the mood itself
+		parent := mood
+		if table.tenseLabel != "" {
+			parent = tview.NewTreeNode(tviewConjugationStyle.tense(table.tenseLabel))
+			mood.AddChild(parent)
+		}
+		for _, form := range table.Forms {
+			parent.AddChild(tview.NewTreeNode(tviewConjugationStyle.person(form.label) + " " + form.Form))
+		}
+	}
+	return node
+}
+
+func (t *Tui) showConjugation(verb string, conjugations *rae.Conjugations) {
+	t.resetState()
+	t.state.conjugation = true
+	t.conjugations = conjugations
+	t.conjugationVerb = verb
+	t.conjugationMood = 0
+
+	t.conjugationView.
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(false).
+		SetBorder(true)
+
+	t.renderConjugationPage()
+	t.pages.SwitchToPage("conjugation")
+	t.app.SetFocus(t.conjugationView)
+}
+
+func (t *Tui) renderConjugationPage() {
+	var filter conjugationFilter
+	title := trf(" Conjugación de «%s» ", t.conjugationVerb)
+	if t.conjugationMood > 0 {
+		mood := conjugationMoods[t.conjugationMood-1]
+		filter.moods = map[string]bool{mood.key: true}
+		title += "— " + mood.label + " "
+	}
+	t.conjugationView.SetTitle(title)
+
+	var text strings.Builder
+	renderConjugationTables(&text, conjugationTables(t.conjugations, filter), tviewConjugationStyle, " ")
+	t.conjugationView.SetText(text.String()).ScrollToBeginning()
+	t.updateFooter()
+}
+
+// cycleConjugationMood shows the next, or previous, mood alone, going through
+// every mood at once between the last and the first
+func (t *Tui) cycleConjugationMood(step int) {
+	n := len(conjugationMoods) + 1
+	t.conjugationMood = ((t.conjugationMood+step)%n + n) % n
+	t.renderConjugationPage()
+}
+
+func (t *Tui) closeConjugation() {
+	t.resetState()
+	t.pages.SwitchToPage("main")
+	t.app.SetFocus(t.resultsView)
+}
+
+// handleConjugationEvent handles the keys of the conjugation page, leaving
+// the rest to its text view so that arrows, page keys, g and G scroll it
+func (t *Tui) handleConjugationEvent(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyEscape:
+		t.closeConjugation()
+		return nil
+
+	case tcell.KeyTab:
+		t.cycleConjugationMood(1)
+		return nil
+
+	case tcell.KeyBacktab:
+		t.cycleConjugationMood(-1)
+		return nil
+	}
+
+	row, col := t.conjugationView.GetScrollOffset()
+	switch t.action(event) {
+	case "quit":
+		t.exit()
+		return nil
+	case "conjugate":
+		t.closeConjugation()
+		return nil
+	case "down":
+		t.conjugationView.ScrollTo(row+1, col)
+		return nil
+	case "up":
+		t.conjugationView.ScrollTo(max(row-1, 0), col)
+		return nil
+	}
+
+	return event
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/rivo/tview"
+)
+
+// exportCurrent writes the current entry to export.dir in export.format and
+// reports where in the footer, until the next key changes it
+func (t *Tui) exportCurrent() {
+	if t.nav.current == nil {
+		return
+	}
+
+	format := outputFormat(t.cfg.Export.Format)
+	item := exportEntry{Entry: *t.nav.current}
+	if t.favorites != nil {
+		if fav, ok := t.favorites.Get(item.Entry.Word); ok {
+			item.Tags = fav.Tags
+		}
+	}
+
+	path := filepath.Join(t.cfg.Export.Dir, exportFileName(item.Entry.Word, format))
+	if err := exportToFile(path, []exportEntry{item}, format); err != nil {
+		t.footer.SetText(tr("[error]No se pudo exportar: ") + tview.Escape(err.Error()))
+		return
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	t.footer.SetText(tr("[success]Exportado a ") + tview.Escape(path))
+}
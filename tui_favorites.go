@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+const (
+	favoriteTagsLabel = "Etiquetas: "
+	favoriteNoteLabel = "Nota: "
+)
+
+func (t *Tui) setupFavoriteForm() {
+	t.favoriteForm.
+		AddInputField(tr(favoriteTagsLabel), "", 40, nil, nil).
+		AddInputField(tr(favoriteNoteLabel), "", 40, nil, nil).
+		AddButton(tr("Guardar"), t.saveFavoriteForm).
+		AddButton(tr("Cancelar"), t.closeFavoriteForm)
+
+	t.favoriteForm.
+		SetBorder(true).
+		SetTitleAlign(tview.AlignLeft)
+	t.favoriteForm.SetButtonsAlign(tview.AlignCenter)
+}
+
+func (t *Tui) toggleFavorite() {
+	if t.favorites == nil || t.nav.current == nil {
+		return
+	}
+
+	_, _ = t.favorites.Toggle(t.nav.current.Word)
+	t.updateHeader()
+}
+
+// editFavorite opens the tags and note editor for the current word,
+// bookmarking it on save if it was not a favorite yet
+func (t *Tui) editFavorite() {
+	if t.favorites == nil || t.nav.current == nil {
+		return
+	}
+
+	item, _ := t.favorites.Get(t.nav.current.Word)
+
+	tags := t.favoriteForm.GetFormItemByLabel(tr(favoriteTagsLabel)).(*tview.InputField)
+	note := t.favoriteForm.GetFormItemByLabel(tr(favoriteNoteLabel)).(*tview.InputField)
+	tags.SetText(strings.Join(item.Tags, ", "))
+	note.SetText(item.Note)
+
+	t.favoriteForm.SetTitle(fmt.Sprintf(" ★ %s ", t.nav.current.Word))
+	t.favoriteForm.SetFocus(0)
+
+	t.state.editingFavorite = true
+	t.updateFooter()
+	t.pages.ShowPage("favorite")
+	t.app.SetFocus(t.favoriteForm)
+}
+
+func (t *Tui) saveFavoriteForm() {
+	if t.favorites != nil && t.nav.current != nil {
+		tags := t.favoriteForm.GetFormItemByLabel(tr(favoriteTagsLabel)).(*tview.InputField)
+		note := t.favoriteForm.GetFormItemByLabel(tr(favoriteNoteLabel)).(*tview.InputField)
+
+		_ = t.favorites.Put(favorite{
+			Word: t.nav.current.Word,
+			Tags: []string{tags.GetText()},
+			Note: strings.TrimSpace(note.GetText()),
+		})
+	}
+
+	t.closeFavoriteForm()
+}
+
+func (t *Tui) closeFavoriteForm() {
+	t.state.editingFavorite = false
+	t.updateFooter()
+	t.updateHeader()
+	t.pages.HidePage("favorite")
+	t.app.SetFocus(t.resultsView)
+}
+
+func (t *Tui) showFavorites() {
+	if t.favorites == nil {
+		return
+	}
+
+	t.resetState()
+	t.state.favorites = true
+	t.updateFooter()
+
+	t.suggestionsList.Clear()
+	t.suggestionsList.AddItem(tr("[accent]Favoritos"), "", 0, nil)
+	t.suggestionsList.AddItem("", "", 0, nil)
+
+	items := t.favorites.List()
+	if len(items) == 0 {
+		hint := trf("[muted]Pulsa %s sobre una palabra para añadirla a favoritos", tview.Escape(t.key("favorite")))
+		t.suggestionsList.AddItem(hint, "", 0, nil)
+	}
+
+	for _, item := range items {
+		text := fmt.Sprintf("[accent]★[-] [::b]%s[::-]", tview.Escape(item.Word))
+		if len(item.Tags) > 0 {
+			text += fmt.Sprintf(" [info]%s[-]", tview.Escape("["+strings.Join(item.Tags, ", ")+"]"))
+		}
+		if item.Note != "" {
+			text += " [muted]— " + tview.Escape(item.Note)
+		}
+		t.suggestionsList.AddItem(text, item.Word, 0, nil)
+	}
+
+	if len(items) > 0 {
+		t.suggestionsList.SetCurrentItem(2)
+	}
+	t.pages.SwitchToPage("list")
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// styleTag matches the color and attribute tags of tview, e.g. "[accent]",
+// "[::b]" or "[-:-:-]"
+var styleTag = regexp.MustCompile(`^\[(?:[a-zA-Z]+|#[0-9a-fA-F]{6}|-)?(?::(?:[a-zA-Z]+|#[0-9a-fA-F]{6}|-)?(?::[bildsruBILDSRU]*-?)?)?\]`)
+
+// Matches are shown in reverse video, keeping the colors of the item
+const (
+	findMatchStart = "[::r]"
+	findMatchEnd   = "[::R]"
+)
+
+// entryFind is a search inside the results view, only touched from the
+// event loop
+type entryFind struct {
+	query string
+	// original holds the text of every node before the matches were
+	// highlighted
+	original map[*tview.TreeNode]string
+	// nodes are the nodes matching, folded or not, count the matches in them
+	nodes []*tview.TreeNode
+	count int
+	// current indexes nodes, -1 while no match is selected
+	current int
+}
+
+// foldRune lowercases r and strips its accent, so that "Árbol" matches
+// "arbol" while keeping one rune per rune
+func foldRune(r rune) rune {
+	switch r = unicode.ToLower(r); r {
+	case 'á', 'à', 'ä':
+		return 'a'
+	case 'é', 'è', 'ë':
+		return 'e'
+	case 'í', 'ì', 'ï':
+		return 'i'
+	case 'ó', 'ò', 'ö':
+		return 'o'
+	case 'ú', 'ù', 'ü':
+		return 'u'
+	}
+	return r
+}
+
+// highlightMatches wraps every occurrence of query in text, ignoring case and
+// accents, with the highlight tags. Tags already in text are kept and never
+// matched. It returns the highlighted text and the number of matches
+func highlightMatches(text, query string) (string, int) {
+	needle := []rune(strings.Map(foldRune, query))
+	if len(needle) == 0 {
+		return text, 0
+	}
+
+	// The visible runes of text, folded, and where each one starts in text
+	var plain []rune
+	var offsets []int
+	for i := 0; i < len(text); {
+		if loc := styleTag.FindStringIndex(text[i:]); loc != nil && loc[1] > 2 {
+			i += loc[1]
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(text[i:])
+		plain = append(plain, foldRune(r))
+		offsets = append(offsets, i)
+		i += size
+	}
+	offsets = append(offsets, len(text))
+
+	var b strings.Builder
+	last, count := 0, 0
+	for i := 0; i+len(needle) <= len(plain); {
+		if string(plain[i:i+len(needle)]) != string(needle) {
+			i++
+			continue
+		}
+		start, end := offsets[i], offsets[i+len(needle)]
+		b.WriteString(text[last:start] + findMatchStart + text[start:end] + findMatchEnd)
+		last = end
+		count++
+		i += len(needle)
+	}
+	if count == 0 {
+		return text, 0
+	}
+	b.WriteString(text[last:])
+	return b.String(), count
+}
+
+// startFind opens the find prompt in place of the footer
+func (t *Tui) startFind() {
+	if len(t.resultsView.GetRoot().GetChildren()) == 0 {
+		return
+	}
+	t.state.finding = true
+
+	query := ""
+	if t.find != nil {
+		query = t.find.query
+	}
+	t.findField.SetText(query)
+
+	t.mainLayout.RemoveItem(t.footer)
+	t.mainLayout.AddItem(t.findField, 1, 1, false)
+	t.app.SetFocus(t.findField)
+}
+
+// closeFindPrompt puts the footer back in place of the find prompt
+func (t *Tui) closeFindPrompt() {
+	t.state.finding = false
+	t.mainLayout.RemoveItem(t.findField)
+	t.mainLayout.AddItem(t.footer, 1, 1, false)
+	t.app.SetFocus(t.resultsView)
+	t.updateFooter()
+}
+
+// applyFind highlights the matches of query, selecting the first one from the
+// selected node on and unfolding the nodes above it
+func (t *Tui) applyFind(query string) {
+	t.restoreFindNodes()
+	if query == "" {
+		t.find = nil
+		return
+	}
+
+	f := &entryFind{query: query, original: make(map[*tview.TreeNode]string), current: -1}
+	nodes := t.resultNodes()
+	from := slices.Index(nodes, t.resultsView.GetCurrentNode())
+	for i, node := range nodes {
+		text := node.GetText()
+		f.original[node] = text
+
+		highlighted, n := highlightMatches(text, query)
+		if n == 0 {
+			continue
+		}
+		node.SetText(highlighted)
+		f.nodes = append(f.nodes, node)
+		f.count += n
+		if f.current < 0 && i >= from {
+			f.current = len(f.nodes) - 1
+		}
+	}
+	if f.current < 0 && len(f.nodes) > 0 {
+		f.current = 0
+	}
+
+	t.find = f
+	if f.current >= 0 {
+		t.revealNode(f.nodes[f.current])
+	}
+}
+
+// restoreFindNodes removes the highlights of the current find. Markers are
+// refreshed, as nodes may have been folded or unfolded meanwhile
+func (t *Tui) restoreFindNodes() {
+	if t.find == nil {
+		return
+	}
+	for node, text := range t.find.original {
+		node.SetText(text)
+		refreshMarker(node)
+	}
+}
+
+// clearFind removes the find and its highlights
+func (t *Tui) clearFind() {
+	t.restoreFindNodes()
+	t.find = nil
+	t.updateFooter()
+}
+
+// cancelFind closes the prompt, removing the matches of what was typed
+func (t *Tui) cancelFind() {
+	t.clearFind()
+	t.closeFindPrompt()
+}
+
+// stepFind selects the next matching node, or the previous one, wrapping
+// around the ends like less and vim do
+func (t *Tui) stepFind(step int) {
+	f := t.find
+	if f == nil || len(f.nodes) == 0 {
+		return
+	}
+	f.current = ((f.current+step)%len(f.nodes) + len(f.nodes)) % len(f.nodes)
+	t.revealNode(f.nodes[f.current])
+	t.updateFooter()
+}
+
+func (t *Tui) setupFindField() {
+	t.findField.
+		SetLabel("/").
+		SetFieldStyle(themeStyle(activeTheme.FooterText, activeTheme.FooterBackground)).
+		SetLabelStyle(themeStyle(activeTheme.FooterText, activeTheme.FooterBackground)).
+		SetChangedFunc(func(text string) {
+			if t.state.finding {
+				t.applyFind(text)
+			}
+		}).
+		SetDoneFunc(func(key tcell.Key) {
+			if key == tcell.KeyEnter {
+				if t.findField.GetText() == "" {
+					t.clearFind()
+				}
+				t.closeFindPrompt()
+			}
+		})
+	t.findField.SetBackgroundColor(themeColor(activeTheme.FooterBackground))
+}
+
+// findStatus describes the find in the footer
+func (t *Tui) findStatus() string {
+	f := t.find
+	if len(f.nodes) == 0 {
+		return trf("[error]«%s»: sin coincidencias[-]", tview.Escape(f.query))
+	}
+	return trf("«%s»: línea %d de %d (%d coincidencias)",
+		tview.Escape(f.query), f.current+1, len(f.nodes), f.count)
+}
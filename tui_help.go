@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// helpEntry is a line of the help overlay. fixed are the keys that cannot be
+// rebound, action the one whose key is shown after them
+type helpEntry struct {
+	fixed, action, label string
+}
+
+// helpPage is the help of a page of the TUI
+type helpPage struct {
+	title   string
+	entries []helpEntry
+}
+
+var (
+	resultsHelp = helpPage{"Resultados", []helpEntry{
+		{"↑", "up", "Subir"},
+		{"↓", "down", "Bajar"},
+		{"PgUp", "half_page_up", "Subir una página, o media"},
+		{"PgDn", "half_page_down", "Bajar una página, o media"},
+		{"Home", "top", "Ir al principio"},
+		{"End", "bottom", "Ir al final"},
+		{"", "next_sense", "Siguiente acepción"},
+		{"", "previous_sense", "Acepción anterior"},
+		{"", "next_homonym", "Siguiente homónimo"},
+		{"", "previous_homonym", "Homónimo anterior"},
+		{"", "goto_locutions", "Ir a las locuciones"},
+		{"", "goto_conjugation", "Ir a la conjugación"},
+		{"Enter", "", "Ir a la palabra enlazada, o plegar"},
+		{"Espacio", "", "Plegar o desplegar"},
+		{"Clic", "", "Ir a la palabra, o plegar en ▾ y ▸"},
+		{"Doble clic", "", "Como Enter"},
+		{"", "fold_all", "Plegar todo"},
+		{"", "unfold_all", "Desplegar todo"},
+		{"←", "back", "Atrás"},
+		{"→", "forward", "Adelante"},
+		{"", "history", "Historial"},
+		{"", "favorite", "Favorito"},
+		{"", "edit_favorite", "Etiquetas y nota del favorito"},
+		{"", "favorites", "Favoritos"},
+		{"", "conjugate", "Conjugar"},
+		{"", "word_of_day", "Palabra del día"},
+		{"", "study", "Estudiar"},
+		{"", "export", "Exportar"},
+		{"", "copy_definition", "Copiar la acepción"},
+		{"", "copy_entry", "Copiar la entrada"},
+		{"", "copy_markdown", "Copiar la entrada en Markdown"},
+		{"", "copy_word", "Copiar la palabra"},
+		{"", "compare", "Comparar"},
+		{"", "find", "Buscar en la entrada"},
+		{"", "find_next", "Siguiente coincidencia"},
+		{"", "find_previous", "Coincidencia anterior"},
+		{"", "new_search", "Nueva búsqueda"},
+		{"ESC", "", "Volver"},
+		{"", "help", "Ayuda"},
+		{"", "quit", "Salir"},
+	}}
+
+	listHelp = helpPage{"Lista", []helpEntry{
+		{"↑", "up", "Subir"},
+		{"↓", "down", "Bajar"},
+		{"Enter", "", "Seleccionar"},
+		{"1-9", "", "Seleccionar sugerencia"},
+		{"", "history", "Historial"},
+		{"", "favorites", "Favoritos"},
+		{"", "word_of_day", "Palabra del día"},
+		{"", "study", "Estudiar"},
+		{"", "new_search", "Nueva búsqueda"},
+		{"ESC", "", "Volver"},
+		{"", "help", "Ayuda"},
+		{"", "quit", "Salir"},
+	}}
+
+	conjugationHelp = helpPage{"Conjugación", []helpEntry{
+		{"↑", "up", "Subir"},
+		{"↓", "down", "Bajar"},
+		{"Tab/Shift+Tab", "", "Cambiar de modo"},
+		{"ESC", "conjugate", "Volver"},
+		{"", "help", "Ayuda"},
+		{"", "quit", "Salir"},
+	}}
+
+	studyHelp = helpPage{"Estudio", []helpEntry{
+		{"Espacio/Enter", "", "Mostrar respuesta"},
+		{"0-2", "", "No la sabía"},
+		{"3", "", "Difícil"},
+		{"4", "", "Bien"},
+		{"5", "", "Fácil"},
+		{"↑", "up", "Subir"},
+		{"↓", "down", "Bajar"},
+		{"ESC", "study", "Terminar"},
+		{"", "help", "Ayuda"},
+		{"", "quit", "Salir"},
+	}}
+
+	compareHelp = helpPage{"Comparación", []helpEntry{
+		{"↑", "up", "Subir"},
+		{"↓", "down", "Bajar"},
+		{"Tab/Shift+Tab", "", "Cambiar de panel"},
+		{"ESC", "compare", "Volver"},
+		{"", "help", "Ayuda"},
+		{"", "quit", "Salir"},
+	}}
+)
+
+// currentHelp returns the help of the page being shown
+func (t *Tui) currentHelp() helpPage {
+	switch {
+	case t.state.conjugation:
+		return conjugationHelp
+	case t.state.study:
+		return studyHelp
+	case t.state.comparing:
+		return compareHelp
+	case t.state.inList():
+		return listHelp
+	}
+	return resultsHelp
+}
+
+// renderHelp lists the entries of page with the keys bound to them, aligned
+func (t *Tui) renderHelp(page helpPage) string {
+	keys := make([]string, len(page.entries))
+	width := 0
+	for i, entry := range page.entries {
+		var parts []string
+		if entry.fixed != "" {
+			parts = append(parts, tr(entry.fixed))
+		}
+		if entry.action != "" {
+			parts = append(parts, t.key(entry.action))
+		}
+		keys[i] = strings.Join(parts, "/")
+		width = max(width, utf8.RuneCountInString(keys[i]))
+	}
+
+	var b strings.Builder
+	for i, entry := range page.entries {
+		fmt.Fprintf(&b, " [accent]%s[-]  %s\n", tview.Escape(padRight(keys[i], width)), tr(entry.label))
+	}
+	return b.String()
+}
+
+// showHelp opens the help of the current page over it
+func (t *Tui) showHelp() {
+	page := t.currentHelp()
+	t.helpView.
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(false).
+		SetBorder(true).
+		SetTitle(trf(" Ayuda — %s ", tr(page.title)))
+	t.helpView.SetText(t.renderHelp(page)).ScrollToBeginning()
+
+	t.state.help = true
+	t.helpFocus = t.app.GetFocus()
+	t.updateFooter()
+	t.pages.ShowPage("help")
+	t.app.SetFocus(t.helpView)
+}
+
+func (t *Tui) closeHelp() {
+	t.state.help = false
+	t.pages.HidePage("help")
+	if t.helpFocus != nil {
+		t.app.SetFocus(t.helpFocus)
+	}
+	t.updateFooter()
+}
+
+// handleHelpEvent closes the help overlay with Escape or the help key,
+// leaving the rest to its text view so that arrows and page keys scroll it
+func (t *Tui) handleHelpEvent(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() == tcell.KeyEscape {
+		t.closeHelp()
+		return nil
+	}
+
+	row, col := t.helpView.GetScrollOffset()
+	switch t.action(event) {
+	case "help":
+		t.closeHelp()
+	case "quit":
+		t.exit()
+	case "down":
+		t.helpView.ScrollTo(row+1, col)
+	case "up":
+		t.helpView.ScrollTo(max(row-1, 0), col)
+	default:
+		if event.Key() != tcell.KeyRune {
+			return event
+		}
+	}
+	return nil
+}
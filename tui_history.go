@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	rae "github.com/rae-api-com/go-rae"
+)
+
+// historyPageSize is the default number of distinct words in the history page
+const historyPageSize = 100
+
+// visit shows a freshly looked up entry, pushing it onto the navigation stack
+// and recording it in the persistent history
+func (t *Tui) visit(res rae.WordEntry) {
+	t.nav.Visit(res)
+	if t.history != nil {
+		_ = t.history.Add(res.Word)
+	}
+	t.displayResults(res)
+}
+
+func (t *Tui) historyBack() {
+	if entry, ok := t.nav.Back(); ok {
+		t.displayResults(entry)
+	}
+}
+
+func (t *Tui) historyForward() {
+	if entry, ok := t.nav.Forward(); ok {
+		t.displayResults(entry)
+	}
+}
+
+func (t *Tui) showHistory() {
+	t.resetState()
+	t.state.history = true
+	t.updateFooter()
+
+	t.suggestionsList.Clear()
+	t.suggestionsList.AddItem(tr("[accent]Historial de búsquedas"), "", 0, nil)
+	t.suggestionsList.AddItem("", "", 0, nil)
+
+	var recent []historyEntry
+	if t.history != nil {
+		recent = t.history.Recent(t.cfg.UI.HistorySize)
+	}
+
+	if len(recent) == 0 {
+		t.suggestionsList.AddItem(tr("[muted]Todavía no has buscado ninguna palabra"), "", 0, nil)
+	}
+
+	for _, entry := range recent {
+		text := fmt.Sprintf(
+			"[::b]%-30s[::-] [muted]%s",
+			entry.Word,
+			entry.Time.Local().Format(time.DateTime),
+		)
+		t.suggestionsList.AddItem(text, entry.Word, 0, nil)
+	}
+
+	if len(recent) > 0 {
+		t.suggestionsList.SetCurrentItem(2)
+	}
+	t.pages.SwitchToPage("list")
+}
@@ -0,0 +1,33 @@
+package main
+
+import "fmt"
+
+// followLinks looks up the only linked word of a results item, or lets the
+// user pick one when the item links to several
+func (t *Tui) followLinks(links []string) {
+	switch len(links) {
+	case 0:
+		return
+	case 1:
+		t.selectWord(links[0])
+	default:
+		t.showLinks(links)
+	}
+}
+
+func (t *Tui) showLinks(links []string) {
+	t.resetState()
+	t.state.links = true
+	t.updateFooter()
+
+	t.suggestionsList.Clear()
+	t.suggestionsList.AddItem(tr("[accent]Ir a:"), "", 0, nil)
+	t.suggestionsList.AddItem("", "", 0, nil)
+
+	for _, link := range links {
+		t.suggestionsList.AddItem(fmt.Sprintf("  → [::u]%s[::-]", link), link, 0, nil)
+	}
+
+	t.suggestionsList.SetCurrentItem(2)
+	t.pages.SwitchToPage("list")
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Header buttons, as regions of the header text
+const (
+	buttonBack      = "back"
+	buttonNewSearch = "new_search"
+	buttonFavorite  = "favorite"
+)
+
+// treeIndent is how far each level of the results tree is drawn from the
+// previous one: the tree graphics plus the default indent of the nodes
+const treeIndent = 3
+
+// setupMouse makes the results, the lists and the header buttons clickable
+// and the views scroll with the wheel
+func (t *Tui) setupMouse() {
+	if !t.cfg.UI.Mouse {
+		return
+	}
+	t.app.EnableMouse(true)
+
+	t.resultsView.SetMouseCapture(t.handleResultsMouse)
+
+	t.header.SetRegions(true).SetHighlightedFunc(func(added, removed, remaining []string) {
+		if len(added) > 0 {
+			t.header.Highlight()
+			t.pressButton(added[0])
+		}
+	})
+
+	// Clicking the header or the footer must not take the focus away from
+	// the page
+	for _, bar := range []*tview.TextView{t.header, t.footer} {
+		bar.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+			if action == tview.MouseLeftDown {
+				return tview.MouseConsumed, nil
+			}
+			return action, event
+		})
+	}
+}
+
+// headerButtons returns the buttons shown in the header
+func (t *Tui) headerButtons() string {
+	buttons := []string{
+		fmt.Sprintf(`["%s"][::r] %s [::R][""]`, buttonBack, tr("← Atrás")),
+		fmt.Sprintf(`["%s"][::r] %s [::R][""]`, buttonNewSearch, tr("Buscar")),
+	}
+	if t.favorites != nil && t.nav.current != nil {
+		star := "☆"
+		if t.favorites.Has(t.nav.current.Word) {
+			star = "★"
+		}
+		buttons = append(buttons, fmt.Sprintf(`["%s"][::r] %s %s [::R][""]`, buttonFavorite, star, tr("Favorito")))
+	}
+	return strings.Join(buttons, " ")
+}
+
+// pressButton runs the header button id, unless a form or the help is over
+// the page
+func (t *Tui) pressButton(id string) {
+	if t.state.typing() || t.state.help {
+		return
+	}
+
+	switch id {
+	case buttonBack:
+		// Like Escape, without quitting when there is nothing to go back to
+		if *t.state == (State{}) && t.find == nil && !t.nav.CanGoBack() {
+			return
+		}
+		t.goBack()
+	case buttonNewSearch:
+		// The search modal only goes over the results and the lists
+		if !t.state.conjugation && !t.state.study && !t.state.comparing {
+			t.openSearch()
+		}
+	case buttonFavorite:
+		if !t.state.inList() {
+			t.toggleFavorite()
+		}
+	}
+}
+
+// handleResultsMouse goes to the word clicked in the results when the node
+// links to it, and folds or unfolds the node when its marker is clicked.
+// Other clicks only select the node, double clicks act like Enter
+func (t *Tui) handleResultsMouse(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+	x, y := event.Position()
+	if !t.resultsView.InRect(x, y) {
+		return action, event
+	}
+	// The search modal, the favorite editor and the help are over the results
+	if t.state.typing() || t.state.help {
+		return tview.MouseConsumed, nil
+	}
+
+	switch action {
+	case tview.MouseLeftClick:
+		node, column := t.resultAt(x, y)
+		if node == nil {
+			return action, event
+		}
+		t.resultsView.SetCurrentNode(node)
+
+		if column < utf8.RuneCountInString(markerExpanded) && len(node.GetChildren()) > 0 {
+			setExpanded(node, !node.IsExpanded())
+		} else if word := linkAt(node, column); word != "" {
+			t.selectWord(word)
+		}
+		return tview.MouseConsumed, nil
+
+	case tview.MouseLeftDoubleClick:
+		return tview.MouseLeftClick, event
+	}
+
+	return action, event
+}
+
+// resultAt returns the node of the results shown at the screen position x, y
+// and the column of x in its text
+func (t *Tui) resultAt(x, y int) (*tview.TreeNode, int) {
+	rectX, rectY, _, _ := t.resultsView.GetInnerRect()
+	row := y - rectY + t.resultsView.GetScrollOffset()
+
+	nodes := t.visibleNodes()
+	if row < 0 || row >= len(nodes) {
+		return nil, 0
+	}
+	return nodes[row], x - rectX - (nodes[row].GetLevel()-1)*treeIndent
+}
+
+// linkAt returns the link of node written at column of its text, if any
+func linkAt(node *tview.TreeNode, column int) string {
+	links, ok := node.GetReference().(resultLinks)
+	if !ok {
+		return ""
+	}
+
+	isWord := func(r rune) bool { return unicode.IsLetter(r) || r == '-' }
+	text := []rune(plainText(node.GetText()))
+	if column < 0 || column >= len(text) || !isWord(text[column]) {
+		return ""
+	}
+	start, end := column, column+1
+	for start > 0 && isWord(text[start-1]) {
+		start--
+	}
+	for end < len(text) && isWord(text[end]) {
+		end++
+	}
+
+	word := string(text[start:end])
+	for _, link := range links {
+		if strings.EqualFold(link, word) {
+			return link
+		}
+	}
+	return ""
+}
+
+// plainText strips the color and attribute tags of text
+func plainText(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		if loc := styleTag.FindStringIndex(text[i:]); loc != nil && loc[1] > 2 {
+			i += loc[1]
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(text[i:])
+		b.WriteRune(r)
+		i += size
+	}
+	return b.String()
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+type sectionKind int
+
+const (
+	sectionHomonym sectionKind = iota
+	sectionSenses
+	sectionLocutions
+	sectionConjugation
+)
+
+// outlineSection is a line of the outline, node being where the section
+// starts in the results tree
+type outlineSection struct {
+	kind  sectionKind
+	label string
+	node  *tview.TreeNode
+	// depth is 1 for the sections of a homonym
+	depth int
+}
+
+// entryOutline lists the sections of the entry shown, and its senses, in the
+// order of the results tree
+type entryOutline struct {
+	sections []outlineSection
+	senses   []*tview.TreeNode
+	// offset is the first section drawn, set while drawing
+	offset int
+}
+
+func (o *entryOutline) add(kind sectionKind, label string, node *tview.TreeNode, depth int) {
+	o.sections = append(o.sections, outlineSection{kind: kind, label: label, node: node, depth: depth})
+}
+
+// starts returns where every section of kind starts
+func (o *entryOutline) starts(kind sectionKind) []*tview.TreeNode {
+	var nodes []*tview.TreeNode
+	for _, s := range o.sections {
+		if s.kind == kind {
+			nodes = append(nodes, s.node)
+		}
+	}
+	return nodes
+}
+
+// resultPositions returns the position of every node of the results, folded
+// or not
+func (t *Tui) resultPositions() map[*tview.TreeNode]int {
+	nodes := t.resultNodes()
+	positions := make(map[*tview.TreeNode]int, len(nodes))
+	for i, node := range nodes {
+		positions[node] = i
+	}
+	return positions
+}
+
+// currentSection returns the index of the section holding the selected node,
+// -1 when it comes before every section
+func (t *Tui) currentSection() int {
+	if t.outline == nil {
+		return -1
+	}
+	positions := t.resultPositions()
+	current, ok := positions[t.resultsView.GetCurrentNode()]
+	if !ok {
+		return -1
+	}
+
+	section := -1
+	for i, s := range t.outline.sections {
+		if positions[s.node] <= current {
+			section = i
+		}
+	}
+	return section
+}
+
+// jump selects the first of targets after the selected node, or with a
+// negative step the last one before it, wrapping around the ends
+func (t *Tui) jump(targets []*tview.TreeNode, step int) {
+	if len(targets) == 0 {
+		return
+	}
+	positions := t.resultPositions()
+	current, ok := positions[t.resultsView.GetCurrentNode()]
+	if !ok {
+		current = -1
+	}
+
+	if step > 0 {
+		target := targets[0]
+		for _, node := range targets {
+			if positions[node] > current {
+				target = node
+				break
+			}
+		}
+		t.revealNode(target)
+		return
+	}
+
+	target := targets[len(targets)-1]
+	for i := len(targets) - 1; i >= 0; i-- {
+		if positions[targets[i]] < current {
+			target = targets[i]
+			break
+		}
+	}
+	t.revealNode(target)
+}
+
+// jumpToSection selects the start of the next section of kind, or the
+// previous one with a negative step
+func (t *Tui) jumpToSection(kind sectionKind, step int) {
+	if t.outline != nil {
+		t.jump(t.outline.starts(kind), step)
+	}
+}
+
+// jumpToSense selects the next sense, or the previous one with a negative step
+func (t *Tui) jumpToSense(step int) {
+	if t.outline != nil {
+		t.jump(t.outline.senses, step)
+	}
+}
+
+// navigateResults runs a paging or jump action on the results
+func (t *Tui) navigateResults(action string) {
+	switch action {
+	case "half_page_down":
+		t.moveHalfPage(1)
+	case "half_page_up":
+		t.moveHalfPage(-1)
+	case "top":
+		t.selectEdge(false)
+	case "bottom":
+		t.selectEdge(true)
+	case "next_sense":
+		t.jumpToSense(1)
+	case "previous_sense":
+		t.jumpToSense(-1)
+	case "next_homonym":
+		t.jumpToSection(sectionHomonym, 1)
+	case "previous_homonym":
+		t.jumpToSection(sectionHomonym, -1)
+	case "goto_locutions":
+		t.jumpToSection(sectionLocutions, 1)
+	case "goto_conjugation":
+		t.jumpToSection(sectionConjugation, 1)
+	}
+}
+
+// moveHalfPage moves the selection of the results by half their height, down
+// or, with a negative step, up
+func (t *Tui) moveHalfPage(step int) {
+	_, _, _, height := t.resultsView.GetInnerRect()
+	t.resultsView.Move(step * max(height/2, 1))
+}
+
+// selectEdge selects the first node of the results, or the last one shown
+func (t *Tui) selectEdge(last bool) {
+	nodes := t.visibleNodes()
+	if len(nodes) == 0 {
+		return
+	}
+	if last {
+		t.resultsView.SetCurrentNode(nodes[len(nodes)-1])
+	} else {
+		t.resultsView.SetCurrentNode(nodes[0])
+	}
+}
+
+// setupOutline draws the outline of the entry in its box, marking the section
+// of the selected node, and jumps to the sections clicked
+func (t *Tui) setupOutline() {
+	t.outlineBox.SetBorder(true).SetTitle(tr(" Esquema "))
+	t.outlineBox.SetDrawFunc(func(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
+		x, y, width, height = x+1, y+1, width-2, height-2
+		if t.outline == nil || width <= 0 || height <= 0 {
+			return x, y, width, height
+		}
+
+		// Scroll so that the current section stays in sight
+		current := t.currentSection()
+		t.outline.offset = max(0, min(t.outline.offset, current), current-height+1)
+
+		for row := 0; row < height && t.outline.offset+row < len(t.outline.sections); row++ {
+			i := t.outline.offset + row
+			s := t.outline.sections[i]
+			text := strings.Repeat("  ", s.depth) + tview.Escape(s.label)
+			if i == current {
+				text = "[accent::b]› " + text
+			} else {
+				text = "  " + text
+			}
+			tview.Print(screen, text, x, y+row, width, tview.AlignLeft, tview.Styles.PrimaryTextColor)
+		}
+		return x, y, width, height
+	})
+
+	t.outlineBox.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		mx, my := event.Position()
+		if !t.outlineBox.InRect(mx, my) {
+			return action, event
+		}
+		if action == tview.MouseLeftClick && t.outline != nil && !t.state.typing() && !t.state.help {
+			_, y, _, _ := t.outlineBox.GetInnerRect()
+			if i := t.outline.offset + my - y; i >= 0 && i < len(t.outline.sections) && my >= y {
+				t.revealNode(t.outline.sections[i].node)
+			}
+		}
+		// The results keep the focus
+		return tview.MouseConsumed, nil
+	})
+}
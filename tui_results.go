@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	rae "github.com/rae-api-com/go-rae"
+	"github.com/rivo/tview"
+)
+
+// Fold markers open the text of the results nodes having children
+const (
+	markerExpanded  = "▾ "
+	markerCollapsed = "▸ "
+)
+
+// resultLinks is the reference of the results nodes linking to other words
+type resultLinks []string
+
+// resultNode returns a results node showing text, navigating to links when
+// selected
+func resultNode(text string, links []string) *tview.TreeNode {
+	node := tview.NewTreeNode(text)
+	if len(links) > 0 {
+		node.SetReference(resultLinks(links))
+	}
+	return node
+}
+
+// refreshMarker puts the marker of the fold state of node in front of its
+// text, if it has children
+func refreshMarker(node *tview.TreeNode) {
+	if len(node.GetChildren()) == 0 {
+		return
+	}
+	text := strings.TrimPrefix(node.GetText(), markerExpanded)
+	text = strings.TrimPrefix(text, markerCollapsed)
+	if node.IsExpanded() {
+		node.SetText(markerExpanded + text)
+	} else {
+		node.SetText(markerCollapsed + text)
+	}
+}
+
+// setExpanded unfolds node, or folds it, keeping its marker up to date
+func setExpanded(node *tview.TreeNode, expanded bool) {
+	if len(node.GetChildren()) == 0 {
+		return
+	}
+	node.SetExpanded(expanded)
+	refreshMarker(node)
+}
+
+// entryTree builds the results tree of res: a branch per homonym holding its
+// origin, its senses with their examples and related words, its locutions
+// and its conjugation. The outline lists where each of them starts
+func (t *Tui) entryTree(res rae.WordEntry) (*tview.TreeNode, *entryOutline) {
+	root := tview.NewTreeNode("")
+	outline := &entryOutline{}
+
+	for _, meaning := range res.Meanings {
+		parent, depth := root, 0
+		if meaning.HomonymIndex > 0 {
+			label := res.Word + superscript(meaning.HomonymIndex)
+			parent = tview.NewTreeNode(fmt.Sprintf("[title][::b]%s[-]", label))
+			root.AddChild(parent)
+			outline.add(sectionHomonym, label, parent, 0)
+			depth = 1
+		}
+
+		if meaning.Origin != nil && meaning.Origin.Raw != "" {
+			parent.AddChild(tview.NewTreeNode(trf("[accent][::b]Origen:[-] %s", meaning.Origin.Raw)))
+		}
+
+		for i, def := range meaning.Definitions {
+			sense := resultNode(highlightReferences(def), definitionLinks(def))
+			if i == 0 {
+				outline.add(sectionSenses, trf("Acepciones (%d)", len(meaning.Definitions)), sense, depth)
+			}
+			outline.senses = append(outline.senses, sense)
+			for _, ex := range def.Examples {
+				sense.AddChild(tview.NewTreeNode(fmt.Sprintf("[muted]↳ %s", ex)))
+			}
+			if len(def.SynonymsV2) > 0 {
+				sense.AddChild(resultNode(
+					trf("[info]Sin.:[-] %s", formatRelatedWords(def.SynonymsV2)),
+					relatedWords(def.SynonymsV2),
+				))
+			}
+			if len(def.AntonymsV2) > 0 {
+				sense.AddChild(resultNode(
+					trf("[error]Ant.:[-] %s", formatRelatedWords(def.AntonymsV2)),
+					relatedWords(def.AntonymsV2),
+				))
+			}
+			parent.AddChild(sense)
+		}
+
+		if len(meaning.Locutions) > 0 {
+			locutions := tview.NewTreeNode(tr("[accent][::b]Locuciones[-]"))
+			for _, loc := range meaning.Locutions {
+				expression := tview.NewTreeNode(fmt.Sprintf("[title][::b]%s[-]", loc.Expression))
+				for _, sense := range loc.Senses {
+					expression.AddChild(resultNode(highlightReferences(sense), definitionLinks(sense)))
+				}
+				locutions.AddChild(expression)
+			}
+			parent.AddChild(locutions)
+			outline.add(sectionLocutions, trf("Locuciones (%d)", len(meaning.Locutions)), locutions, depth)
+		}
+
+		if meaning.Conjugations != nil {
+			conjugation := t.conjugationNode(res.Word, meaning.Conjugations)
+			parent.AddChild(conjugation)
+			outline.add(sectionConjugation, tr("Conjugación"), conjugation, depth)
+		}
+	}
+
+	root.Walk(func(node, parent *tview.TreeNode) bool {
+		if parent != nil {
+			refreshMarker(node)
+		}
+		return true
+	})
+	return root, outline
+}
+
+// resultNodes returns every node of the results, folded or not, in the order
+// they are shown
+func (t *Tui) resultNodes() []*tview.TreeNode {
+	var nodes []*tview.TreeNode
+	t.resultsView.GetRoot().Walk(func(node, parent *tview.TreeNode) bool {
+		if parent != nil {
+			nodes = append(nodes, node)
+		}
+		return true
+	})
+	return nodes
+}
+
+// visibleNodes returns the nodes of the results not hidden in a folded one,
+// as they are shown
+func (t *Tui) visibleNodes() []*tview.TreeNode {
+	var nodes []*tview.TreeNode
+	t.resultsView.GetRoot().Walk(func(node, parent *tview.TreeNode) bool {
+		if parent != nil {
+			nodes = append(nodes, node)
+		}
+		return node.IsExpanded()
+	})
+	return nodes
+}
+
+// selectResult follows the links of the selected node, or folds it or unfolds
+// it when it has none
+func (t *Tui) selectResult(node *tview.TreeNode) {
+	if links, ok := node.GetReference().(resultLinks); ok {
+		t.followLinks(links)
+		return
+	}
+	setExpanded(node, !node.IsExpanded())
+}
+
+// toggleFold folds the selected node of the results, or unfolds it
+func (t *Tui) toggleFold() {
+	if node := t.resultsView.GetCurrentNode(); node != nil {
+		setExpanded(node, !node.IsExpanded())
+	}
+}
+
+// foldAll folds every node of the results, selecting the top level one the
+// selection was under so that it stays visible
+func (t *Tui) foldAll() {
+	path := t.resultsView.GetPath(t.resultsView.GetCurrentNode())
+	for _, node := range t.resultNodes() {
+		setExpanded(node, false)
+	}
+	if len(path) > 1 {
+		t.resultsView.SetCurrentNode(path[1])
+	}
+}
+
+func (t *Tui) unfoldAll() {
+	for _, node := range t.resultNodes() {
+		setExpanded(node, true)
+	}
+}
+
+// revealNode unfolds the nodes above node and selects it
+func (t *Tui) revealNode(node *tview.TreeNode) {
+	path := t.resultsView.GetPath(node)
+	for i := 1; i < len(path)-1; i++ {
+		setExpanded(path[i], true)
+	}
+	t.resultsView.SetCurrentNode(node)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	rae "github.com/rae-api-com/go-rae"
+)
+
+// spinnerFrames animate the footer while a lookup is in flight
+var spinnerFrames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+const spinnerInterval = 100 * time.Millisecond
+
+// lookupOutcome is everything a lookup fetched off the event loop. The fuzzy
+// search is only run when the word was not found and has no suggestions
+type lookupOutcome struct {
+	entry         rae.WordEntry
+	err           error
+	lemma         *lemmaMatch
+	searchResults []rae.SearchResult
+	searchErr     error
+}
+
+// search looks word up in the background, superseding any lookup still in
+// flight. It must be called from the event loop, or before the app runs
+func (t *Tui) search(ctx context.Context, word string) {
+	t.startLookup(ctx, word, t.showLookup)
+}
+
+// startLookup looks word up in the background like search, handing the
+// outcome to show on the event loop
+func (t *Tui) startLookup(ctx context.Context, word string, show func(lookupOutcome)) {
+	if t.searchCancel != nil {
+		t.searchCancel()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	t.searchSeq++
+	seq := t.searchSeq
+	t.searchCancel = cancel
+	t.searchWord = word
+	t.spinnerFrame = 0
+	t.state.loading = true
+	t.updateFooter()
+
+	go t.spin(ctx, seq)
+	go func() {
+		out := lookup(ctx, t.cli, word)
+
+		t.app.QueueUpdateDraw(func() {
+			// A newer search started or this one was cancelled meanwhile
+			if seq != t.searchSeq || ctx.Err() != nil {
+				return
+			}
+			t.finishSearch()
+			show(out)
+		})
+	}()
+}
+
+func lookup(ctx context.Context, cli dictionary, word string) lookupOutcome {
+	res, err := cli.Word(ctx, word)
+	out := lookupOutcome{entry: res, err: err}
+
+	// An inflected form resolves to its dictionary entry before suggestions
+	if err != nil && shouldLemmatize(res, err) && ctx.Err() == nil {
+		if match, ok := lemmatize(ctx, lemmaSource(cli, err), word); ok {
+			return lookupOutcome{entry: match.Entry, lemma: &match}
+		}
+	}
+
+	if err != nil && len(res.Suggestions) == 0 && ctx.Err() == nil {
+		out.searchResults, out.searchErr = cli.Search(ctx, word)
+	}
+
+	return out
+}
+
+// spin animates the footer until ctx is done
+func (t *Tui) spin(ctx context.Context, seq uint64) {
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.app.QueueUpdateDraw(func() {
+				if seq != t.searchSeq || !t.state.loading {
+					return
+				}
+				t.spinnerFrame++
+				t.updateFooter()
+			})
+		}
+	}
+}
+
+func (t *Tui) finishSearch() {
+	if t.searchCancel != nil {
+		t.searchCancel()
+		t.searchCancel = nil
+	}
+	t.state.loading = false
+	t.updateFooter()
+}
+
+// cancelSearch aborts the lookup in flight and stays where the user was,
+// falling back to the results page if a list was left behind
+func (t *Tui) cancelSearch() {
+	t.finishSearch()
+
+	if !t.state.searching && !t.state.inList() {
+		t.pages.SwitchToPage("main")
+	}
+}
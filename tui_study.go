@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	rae "github.com/rae-api-com/go-rae"
+	"github.com/rivo/tview"
+)
+
+// studySession is the flashcard review in progress, only touched from the
+// event loop
+type studySession struct {
+	queue    []studyCard
+	current  int
+	revealed bool
+	reviewed int
+	// message replaces the cards, e.g. when there is nothing to study
+	message string
+
+	// The entry of the current card, loaded in the background
+	seq   uint64
+	entry *rae.WordEntry
+	err   error
+}
+
+func (s *studySession) done() bool {
+	return s.message != "" || s.current >= len(s.queue)
+}
+
+// showStudy starts a review of the cards due, after adding the words of the
+// configured source to the deck
+func (t *Tui) showStudy() {
+	if t.study == nil {
+		return
+	}
+
+	t.resetState()
+	t.state.study = true
+	t.studySession = &studySession{}
+
+	now := time.Now()
+	words, err := sourceWords(t.cfg.Study.Source, t.history, t.favorites)
+	if err == nil {
+		_, err = t.study.Sync(words, now)
+	}
+	t.studySession.queue = t.study.Due(now, t.cfg.Study.NewCards)
+
+	switch {
+	case len(t.studySession.queue) > 0:
+	case err != nil && !errors.Is(err, errNoWords):
+		t.studySession.message = "[error]" + tview.Escape(err.Error())
+	case errors.Is(err, errNoWords) && t.cfg.Study.Source == wordSourceFavorites:
+		t.studySession.message = trf(
+			"[muted]Todavía no hay tarjetas: pulsa %s sobre una palabra para añadirla a favoritos",
+			tview.Escape(t.key("favorite")),
+		)
+	case errors.Is(err, errNoWords):
+		t.studySession.message = tr("[muted]Todavía no hay tarjetas: busca algunas palabras primero")
+	default:
+		t.studySession.message = tr("[success]No hay tarjetas pendientes[-]")
+		if next := t.study.Stats(now).NextDue; !next.IsZero() {
+			t.studySession.message += tr("\n\n[muted]Próximo repaso: ") + next.Local().Format(time.DateTime)
+		}
+	}
+
+	t.studyView.
+		SetDynamicColors(true).
+		SetWrap(true).
+		SetWordWrap(true).
+		SetBorder(true)
+
+	t.loadStudyCard()
+	t.pages.SwitchToPage("study")
+	t.app.SetFocus(t.studyView)
+}
+
+// loadStudyCard shows the front of the current card and looks its word up
+func (t *Tui) loadStudyCard() {
+	s := t.studySession
+	s.revealed = false
+	s.entry, s.err = nil, nil
+	s.seq++
+
+	if !s.done() {
+		seq, word := s.seq, s.queue[s.current].Word
+		go func() {
+			entry, err := t.cli.Word(context.Background(), word)
+			t.app.QueueUpdateDraw(func() {
+				if t.studySession != s || s.seq != seq {
+					return
+				}
+				s.entry, s.err = &entry, err
+				t.renderStudyCard()
+			})
+		}()
+	}
+	t.renderStudyCard()
+}
+
+func (t *Tui) renderStudyCard() {
+	s := t.studySession
+	defer t.updateFooter()
+
+	if s.message != "" {
+		t.studyView.SetTitle(tr(" Estudio "))
+		t.studyView.SetText("\n" + s.message).ScrollToBeginning()
+		return
+	}
+	if s.done() {
+		t.studyView.SetTitle(tr(" Estudio "))
+		t.studyView.SetText(trf(
+			"\n[success::b]¡Sesión terminada![-::-]\n\nHas repasado %d tarjetas.", s.reviewed,
+		)).ScrollToBeginning()
+		return
+	}
+
+	card := s.queue[s.current]
+	t.studyView.SetTitle(trf(" Estudio — %d/%d ", s.current+1, len(s.queue)))
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "\n[::b]%s[::-]\n", tview.Escape(card.Word))
+	if card.isNew() {
+		text.WriteString(tr("[info]nueva[-]") + "\n")
+	}
+	text.WriteString("\n")
+
+	switch {
+	case !s.revealed:
+		text.WriteString(tr("[muted]¿Recuerdas qué significa?"))
+	case s.entry == nil:
+		text.WriteString(tr("[muted]Cargando…"))
+	case s.err != nil:
+		text.WriteString(trf("[error]No se pudo cargar la definición: %s", tview.Escape(s.err.Error())))
+	default:
+		for _, meaning := range s.entry.Meanings {
+			for _, def := range meaning.Definitions {
+				fmt.Fprintf(&text, "%s\n", tview.Escape(def.Raw))
+			}
+		}
+	}
+	t.studyView.SetText(text.String()).ScrollToBeginning()
+}
+
+// gradeStudyCard records the grade of the current card and moves on. Cards
+// not remembered come back at the end of the session
+func (t *Tui) gradeStudyCard(grade int) {
+	s := t.studySession
+	card := s.queue[s.current]
+
+	if reviewed, err := t.study.Review(card.Word, grade, time.Now()); err == nil {
+		card = reviewed
+	}
+	s.reviewed++
+	if grade < studyPassGrade {
+		s.queue = append(s.queue, card)
+	}
+	s.current++
+	t.loadStudyCard()
+}
+
+func (t *Tui) closeStudy() {
+	t.studySession = nil
+	t.resetState()
+	t.pages.SwitchToPage("main")
+	t.app.SetFocus(t.resultsView)
+}
+
+// handleStudyEvent reveals the back of the card with Space or Enter, then
+// takes a grade from 0 to 5
+func (t *Tui) handleStudyEvent(event *tcell.EventKey) *tcell.EventKey {
+	s := t.studySession
+
+	switch event.Key() {
+	case tcell.KeyEscape:
+		t.closeStudy()
+		return nil
+
+	case tcell.KeyEnter:
+		if !s.done() && !s.revealed {
+			s.revealed = true
+			t.renderStudyCard()
+		}
+		return nil
+
+	case tcell.KeyRune:
+		r := event.Rune()
+		switch {
+		case r == ' ' && !s.done() && !s.revealed:
+			s.revealed = true
+			t.renderStudyCard()
+			return nil
+		case r >= '0' && r <= '0'+studyMaxGrade && !s.done() && s.revealed:
+			t.gradeStudyCard(int(r - '0'))
+			return nil
+		}
+	}
+
+	row, col := t.studyView.GetScrollOffset()
+	switch t.action(event) {
+	case "quit":
+		t.exit()
+	case "study":
+		t.closeStudy()
+	case "down":
+		t.studyView.ScrollTo(row+1, col)
+	case "up":
+		t.studyView.ScrollTo(max(row-1, 0), col)
+	default:
+		if event.Key() != tcell.KeyRune {
+			return event
+		}
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package main
+
+import "time"
+
+// showWordOfDay looks up the word of the day from the configured source,
+// falling back to the bundled word list while the source is empty
+func (t *Tui) showWordOfDay() {
+	words, err := sourceWords(t.cfg.Wotd.Source, t.history, t.favorites)
+	if err != nil {
+		if words, err = sourceWords(wordSourceSeed, nil, nil); err != nil {
+			return
+		}
+	}
+	t.selectWord(dailyWord(words, time.Now()))
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+)
+
+// seedWords is the word list bundled with rae-tui, one word per line
+//
+//go:embed seed_words.txt
+var seedWords string
+
+// Sources of the words picked by `rae-tui random` and `rae-tui wotd`
+const (
+	wordSourceSeed      = "seed"
+	wordSourceHistory   = "history"
+	wordSourceFavorites = "favorites"
+)
+
+var wordSources = []string{wordSourceSeed, wordSourceHistory, wordSourceFavorites}
+
+// errNoWords is returned when the chosen source has no words yet
+var errNoWords error = messageError("no hay palabras entre las que elegir")
+
+func validWordSource(source string) error {
+	if !slices.Contains(wordSources, source) {
+		return fmt.Errorf(tr("origen de palabras desconocido: %q (usa %s)"), source, strings.Join(wordSources, ", "))
+	}
+	return nil
+}
+
+// sourceWords returns the words of source, sorted so that the pick of a date
+// only changes when the words themselves do
+func sourceWords(source string, history *lookupHistory, favorites *favoriteStore) ([]string, error) {
+	var words []string
+	switch source {
+	case wordSourceSeed:
+		var err error
+		if words, err = readBatchWords(strings.NewReader(seedWords)); err != nil {
+			return nil, err
+		}
+	case wordSourceHistory:
+		if history != nil {
+			for _, entry := range history.Recent(0) {
+				words = append(words, entry.Word)
+			}
+		}
+	case wordSourceFavorites:
+		if favorites != nil {
+			for _, item := range favorites.List() {
+				words = append(words, item.Word)
+			}
+		}
+	default:
+		return nil, validWordSource(source)
+	}
+
+	if len(words) == 0 {
+		return nil, errNoWords
+	}
+	slices.Sort(words)
+	return slices.Compact(words), nil
+}
+
+// dailyWord picks the word of date, the same for everyone with the same
+// words and calendar day
+func dailyWord(words []string, date time.Time) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(date.Format(time.DateOnly)))
+	return words[h.Sum64()%uint64(len(words))]
+}
+
+func randomWord(words []string) string {
+	return words[rand.IntN(len(words))]
+}
+
+// pickWord loads the words of source and picks one, the word of date if
+// daily is set or a random one otherwise
+func pickWord(source string, daily bool, date time.Time) (string, error) {
+	var history *lookupHistory
+	var favorites *favoriteStore
+	var err error
+	switch source {
+	case wordSourceHistory:
+		history, err = loadHistory(defaultHistoryPath())
+	case wordSourceFavorites:
+		favorites, err = loadFavorites(defaultFavoritesPath())
+	}
+	if err != nil {
+		return "", err
+	}
+
+	words, err := sourceWords(source, history, favorites)
+	if err != nil {
+		return "", err
+	}
+	if daily {
+		return dailyWord(words, date), nil
+	}
+	return randomWord(words), nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDailyWord(t *testing.T) {
+	words, err := sourceWords(wordSourceSeed, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := time.Date(2024, time.March, 9, 8, 0, 0, 0, time.UTC)
+	word := dailyWord(words, day)
+	if !slices.Contains(words, word) {
+		t.Fatalf("dailyWord = %q, not one of the words", word)
+	}
+	if later := dailyWord(words, day.Add(15*time.Hour)); later != word {
+		t.Errorf("dailyWord changed within the day: %q, then %q", word, later)
+	}
+
+	// Over a month the word must change from day to day
+	picks := make(map[string]bool)
+	for i := range 30 {
+		picks[dailyWord(words, day.AddDate(0, 0, i))] = true
+	}
+	if len(picks) < 2 {
+		t.Errorf("dailyWord picked %d different words in 30 days", len(picks))
+	}
+}
+
+func TestSourceWords(t *testing.T) {
+	favorites, err := loadFavorites(filepath.Join(t.TempDir(), "favorites.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sourceWords(wordSourceFavorites, nil, favorites); !errors.Is(err, errNoWords) {
+		t.Errorf("no favorites: error = %v, want errNoWords", err)
+	}
+	if _, err := sourceWords("diccionario", nil, nil); err == nil {
+		t.Error("unknown source: no error")
+	}
+
+	for _, word := range []string{"perro", "casa"} {
+		if err := favorites.Put(favorite{Word: word}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	words, err := sourceWords(wordSourceFavorites, nil, favorites)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"casa", "perro"}; !slices.Equal(words, want) {
+		t.Errorf("words = %q, want %q", words, want)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	rae "github.com/rae-api-com/go-rae"
+	"github.com/rivo/tview"
+)
+
+var (
+	// definitionPrefix matches the sense number and grammatical abbreviations
+	// that open a definition, e.g. "1. f. " or "3. tr. U. t. c. prnl. "
+	definitionPrefix = regexp.MustCompile(`^\s*\d+\.\s+(?:[\p{L}]{1,6}\.\s+)*`)
+
+	// seeReference matches explicit references such as "V. palabra"
+	seeReference = regexp.MustCompile(`\bV\.\s+([\p{L}-]+)`)
+)
+
+// definitionLinks returns the words a definition can navigate to: explicit
+// references first, then related words, then every word of the text
+func definitionLinks(def rae.Definition) []string {
+	links := make([]string, 0, len(def.CrossReferences)+len(def.SynonymsV2)+len(def.AntonymsV2))
+	links = append(links, referencedWords(def)...)
+	links = append(links, relatedWords(def.SynonymsV2)...)
+	links = append(links, relatedWords(def.AntonymsV2)...)
+	links = append(links, definitionWords(def.Raw)...)
+	return uniqueWords(links)
+}
+
+// referencedWords returns the cross references of def plus any "V. palabra"
+// found in its text
+func referencedWords(def rae.Definition) []string {
+	refs := append([]string(nil), def.CrossReferences...)
+	for _, match := range seeReference.FindAllStringSubmatch(def.Raw, -1) {
+		refs = append(refs, match[1])
+	}
+	return refs
+}
+
+func relatedWords(words []rae.RelatedWord) []string {
+	out := make([]string, 0, len(words))
+	for _, w := range words {
+		out = append(out, w.Word)
+	}
+	return out
+}
+
+// definitionWords splits the text of a definition into words, skipping the
+// sense number and abbreviations at its start
+func definitionWords(raw string) []string {
+	text := definitionPrefix.ReplaceAllString(raw, "")
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '-'
+	})
+
+	words := make([]string, 0, len(fields))
+	for _, field := range fields {
+		field = strings.Trim(field, "-")
+		if len([]rune(field)) < 2 {
+			continue
+		}
+		words = append(words, strings.ToLower(field))
+	}
+	return words
+}
+
+func uniqueWords(words []string) []string {
+	seen := make(map[string]struct{}, len(words))
+	out := make([]string, 0, len(words))
+	for _, w := range words {
+		w = strings.TrimSpace(w)
+		key := cacheKey(w)
+		if key == "" {
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, w)
+	}
+	return out
+}
+
+// highlightReferences underlines the explicit references of def inside its
+// text so they stand out as navigable. The text is escaped first, so that
+// brackets of the definition are not taken for tags
+func highlightReferences(def rae.Definition) string {
+	text := seeReference.ReplaceAllString(tview.Escape(def.Raw), "V. [::u]$1[::-]")
+	for _, ref := range def.CrossReferences {
+		if ref == "" || strings.Contains(text, "[::u]"+ref) {
+			continue
+		}
+		// Only a whole word is underlined, never part of a longer one
+		whole := regexp.MustCompile(`(?:^|[^\p{L}])(` + regexp.QuoteMeta(ref) + `)(?:[^\p{L}]|$)`)
+		if m := whole.FindStringSubmatchIndex(text); m != nil {
+			text = text[:m[2]] + "[::u]" + ref + "[::-]" + text[m[3]:]
+		}
+	}
+	return text
+}
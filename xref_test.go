@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	rae "github.com/rae-api-com/go-rae"
+)
+
+func TestHighlightReferences(t *testing.T) {
+	tests := []struct {
+		name string
+		def  rae.Definition
+		want string
+	}{
+		{
+			name: "see reference",
+			def:  rae.Definition{Raw: "1. f. Casa grande. V. caserío"},
+			want: "1. f. Casa grande. V. [::u]caserío[::-]",
+		},
+		{
+			name: "whole word only",
+			def:  rae.Definition{Raw: "1. f. Casona, mayor que la casa.", CrossReferences: []string{"casa"}},
+			want: "1. f. Casona, mayor que la [::u]casa[::-].",
+		},
+		{
+			name: "part of a word",
+			def:  rae.Definition{Raw: "1. tr. Acción de casar.", CrossReferences: []string{"casa"}},
+			want: "1. tr. Acción de casar.",
+		},
+		{
+			name: "brackets",
+			def:  rae.Definition{Raw: "1. m. Texto [sic] de la casa.", CrossReferences: []string{"casa"}},
+			want: "1. m. Texto [sic[] de la [::u]casa[::-].",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := highlightReferences(tt.def); got != tt.want {
+				t.Errorf("highlightReferences = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}